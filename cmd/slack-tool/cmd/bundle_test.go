@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseBundleSources(t *testing.T) {
+	input := `# comment
+https://acme.slack.com/archives/C12345678/p1234567890123456
+
+https://acme.slack.com/archives/C87654321 oldest=2024-01-01 latest=2024-01-31 limit=200
+`
+	sources, err := parseBundleSources(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []bundleSource{
+		{URL: "https://acme.slack.com/archives/C12345678/p1234567890123456"},
+		{URL: "https://acme.slack.com/archives/C87654321", Oldest: "2024-01-01", Latest: "2024-01-31", Limit: 200},
+	}
+	if len(sources) != len(want) {
+		t.Fatalf("parseBundleSources = %+v, want %+v", sources, want)
+	}
+	for i := range want {
+		if sources[i] != want[i] {
+			t.Errorf("source %d = %+v, want %+v", i, sources[i], want[i])
+		}
+	}
+
+	for _, line := range []string{"https://acme.slack.com/archives/C1 limit=0", "https://acme.slack.com/archives/C1 since=2024-01-01"} {
+		if _, err := parseBundleSources(strings.NewReader(line)); err == nil {
+			t.Errorf("parseBundleSources(%q) succeeded, want error", line)
+		}
+	}
+}
+
+func TestBundleSourceWithDefaults(t *testing.T) {
+	// --week 2024-W18 --limit 100 に相当する既定値
+	defaults := bundleSource{Limit: 100, Oldest: "1714316400", Latest: "1714921200"}
+
+	tests := []struct {
+		name   string
+		source bundleSource
+		want   bundleSource
+	}{
+		{"no range", bundleSource{}, defaults},
+		{"limit only", bundleSource{Limit: 20}, bundleSource{Limit: 20, Oldest: "1714316400", Latest: "1714921200"}},
+		{"oldest only", bundleSource{Oldest: "2024-01-01"}, bundleSource{Limit: 100, Oldest: "2024-01-01"}},
+		{"latest only", bundleSource{Latest: "2024-01-31"}, bundleSource{Limit: 100, Latest: "2024-01-31"}},
+		{"both", bundleSource{Oldest: "2024-01-01", Latest: "2024-01-31"}, bundleSource{Limit: 100, Oldest: "2024-01-01", Latest: "2024-01-31"}},
+	}
+
+	for _, tt := range tests {
+		if got := tt.source.withDefaults(defaults); got != tt.want {
+			t.Errorf("%s: withDefaults = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
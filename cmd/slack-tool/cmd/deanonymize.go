@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/shellme/slack-tool/internal/i18n"
+	"github.com/spf13/cobra"
+)
+
+var deanonymizeCmd = &cobra.Command{
+	Use:   "deanonymize [file]",
+	Short: "匿名化した内容を対応表で元に戻す",
+	Long: `--anonymize-map で保存した対応表を使って、仮名（User A など）とマスクした値（[email-1] など）を元に戻します。
+AIの回答など、匿名化した内容をもとに作成したテキストにも使用できます。
+ファイルを省略した場合は標準入力から読み込みます。
+
+例:
+  slack-tool get message "https://your-workspace.slack.com/archives/C12345678/p1234567890123456" --thread --anonymize-map mapping.json --output thread.md
+  slack-tool deanonymize answer.md --map mapping.json
+  pbpaste | slack-tool deanonymize --map mapping.json`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		mappingFile, _ := cmd.Flags().GetString("map")
+		if mappingFile == "" {
+			fmt.Fprint(os.Stderr, i18n.T("エラー: --map で対応表のファイルを指定してください\n"))
+			os.Exit(1)
+		}
+		mapping, err := loadAnonymizeMapping(mappingFile)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				err = fmt.Errorf(i18n.T("対応表が見つかりません: %s"), mappingFile)
+			}
+			fmt.Fprintf(os.Stderr, i18n.T("エラー: %v\n"), err)
+			os.Exit(1)
+		}
+
+		// 入力を読み込み（ファイル省略時は標準入力）
+		var input []byte
+		if len(args) > 0 {
+			input, err = os.ReadFile(args[0])
+		} else {
+			input, err = io.ReadAll(os.Stdin)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("エラー: 入力の読み込みに失敗しました: %v\n"), err)
+			os.Exit(1)
+		}
+
+		restored := mapping.Restore(string(input))
+
+		outputFile, _ := cmd.Flags().GetString("output")
+		if outputFile == "" {
+			fmt.Print(restored)
+			return
+		}
+		if err := os.WriteFile(outputFile, []byte(restored), 0600); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("エラー: ファイルの保存に失敗しました: %v\n"), err)
+			os.Exit(1)
+		}
+		fmt.Printf(i18n.T("元に戻した内容を %s に保存しました\n"), outputFile)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(deanonymizeCmd)
+
+	deanonymizeCmd.Flags().StringP("map", "m", "", "--anonymize-map で保存した対応表のファイル")
+	deanonymizeCmd.Flags().StringP("output", "o", "", "出力ファイル名を指定（省略時は標準出力）")
+}
@@ -2,21 +2,24 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"sort"
 	"strings"
 
-	"github.com/shellme/slack-tool/internal/config"
+	"github.com/shellme/slack-tool/internal/i18n"
 	"github.com/shellme/slack-tool/internal/slack"
 	"github.com/spf13/cobra"
 )
 
 var reactionsCmd = &cobra.Command{
-	Use:   "reactions [message-url]",
+	Use:   "reactions [message-url...]",
 	Short: "指定した投稿のリアクション一覧を取得",
 	Long:  "Slack投稿のリアクション一覧を取得するためのコマンドです。",
 	Args:  cobra.MinimumNArgs(0),
 	Run: func(cmd *cobra.Command, args []string) {
-		if len(args) > 0 {
+		search, _ := cmd.Flags().GetString("search")
+		if len(args) > 0 || search != "" {
 			// 引数がある場合は直接リアクション取得処理を実行
 			getReactionsCmd.Run(cmd, args)
 		} else {
@@ -27,54 +30,111 @@ var reactionsCmd = &cobra.Command{
 }
 
 var getReactionsCmd = &cobra.Command{
-	Use:   "reactions <message-url>",
+	Use:   "reactions <message-url...>",
 	Short: "指定した投稿のリアクション一覧を取得",
 	Long: `指定したSlack投稿のリアクション一覧を取得します。
 
+複数の投稿URLを指定するか --search で投稿を検索した場合は、
+ユーザー×投稿の出欠表（各セルにリアクション、ユーザーごとの合計付き）を出力します。
+出欠表の出力形式は --format で指定します（省略時は表形式、csv / tsv / json）。
+
 例:
   slack-tool reactions "https://workspace.slack.com/archives/C12345678/p1234567890123456"
   slack-tool get reactions "https://workspace.slack.com/archives/C12345678/p1234567890123456"
   slack-tool reactions "https://workspace.slack.com/archives/C12345678/p1234567890123456" --filter ":参加します:"
   slack-tool reactions "https://workspace.slack.com/archives/C12345678/p1234567890123456" --email
   slack-tool reactions "https://workspace.slack.com/archives/C12345678/p1234567890123456" --output reactions.txt
-  slack-tool reactions "https://workspace.slack.com/archives/C12345678/p1234567890123456" --filter "承知_しました" --email --output participants.txt`,
-	Args: cobra.ExactArgs(1),
+  slack-tool reactions "https://workspace.slack.com/archives/C12345678/p1234567890123456" --filter "承知_しました" --email --output participants.txt
+  slack-tool reactions "https://workspace.slack.com/archives/C12345678/p1234567890123456" --missing
+  slack-tool reactions "https://workspace.slack.com/archives/C12345678/p1234567890123456" --missing --filter ":参加します:" --email --simple
+  slack-tool reactions "https://workspace.slack.com/archives/C12345678/p1234567890123456" --format csv --output reactions.csv
+  slack-tool reactions "https://workspace.slack.com/archives/C12345678/p1234567890123456" --filter ":参加します:" --format ics-attendees
+  slack-tool reactions "https://workspace.slack.com/archives/C12345678/p1234567890123456" --sort count
+  slack-tool reactions "https://workspace.slack.com/archives/C12345678/p1234567890123456" --by-user --sort name
+  slack-tool reactions "https://workspace.slack.com/archives/C12345678/p1111111111111111" "https://workspace.slack.com/archives/C12345678/p2222222222222222" --filter ":参加します:"
+  slack-tool reactions --search "週次勉強会 in:#events after:2024-04-01" --filter ":参加します:" --format csv --output attendance.csv`,
+	Args: cobra.ArbitraryArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		messageURL := args[0]
+		search, _ := cmd.Flags().GetString("search")
+		if len(args) == 0 && search == "" {
+			fmt.Fprint(os.Stderr, i18n.T("エラー: 投稿URLまたは --search を指定してください\n"))
+			os.Exit(1)
+		}
 
-		cm := config.NewConfigManager()
-		cfg, err := cm.Load()
+		// 出力形式を検証（出欠表の出力形式は runReactionMatrix で検証）
+		matrixMode := len(args) > 1 || search != ""
+		if format, _ := cmd.Flags().GetString("format"); !matrixMode && format != "" && !isReactionExportFormat(format) {
+			fmt.Fprintf(os.Stderr, i18n.T("エラー: サポートされていない出力形式です: %s（%s のいずれかを指定してください）\n"), format, strings.Join(reactionExportFormats, ", "))
+			os.Exit(1)
+		}
+
+		// 並び順を検証
+		if sortOrder, _ := cmd.Flags().GetString("sort"); sortOrder != "slack" && sortOrder != "count" && sortOrder != "name" {
+			fmt.Fprintf(os.Stderr, i18n.T("エラー: 無効な並び順です: %s（slack / count / name のいずれかを指定してください）\n"), sortOrder)
+			os.Exit(1)
+		}
+
+		cfg, err := loadConfig(cmd, args...)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "エラー: 設定の読み込みに失敗しました: %v\n", err)
+			fmt.Fprintf(os.Stderr, i18n.T("エラー: 設定の読み込みに失敗しました: %v\n"), err)
 			os.Exit(1)
 		}
 
 		client := slack.NewClient(cfg.SlackToken)
 
 		if err := client.TestConnection(); err != nil {
-			fmt.Fprintf(os.Stderr, "エラー: Slack APIへの接続に失敗しました: %v\n", err)
+			fmt.Fprintf(os.Stderr, i18n.T("エラー: Slack APIへの接続に失敗しました: %v\n"), err)
 			os.Exit(1)
 		}
 
+		// 複数の投稿が対象の場合は出欠表を出力
+		if matrixMode {
+			loc, err := resolveLocation(cmd, cfg)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, i18n.T("エラー: %v\n"), err)
+				os.Exit(1)
+			}
+			runReactionMatrix(cmd, client, args, search, loc)
+			return
+		}
+		messageURL := args[0]
+
 		// オプションを取得
 		filter, _ := cmd.Flags().GetString("filter")
 		email, _ := cmd.Flags().GetBool("email")
 		outputFile, _ := cmd.Flags().GetString("output")
 		simple, _ := cmd.Flags().GetBool("simple")
+		missing, _ := cmd.Flags().GetBool("missing")
+		format, _ := cmd.Flags().GetString("format")
+		sortOrder, _ := cmd.Flags().GetString("sort")
+		byUser, _ := cmd.Flags().GetBool("by-user")
 
-		// リアクション一覧を取得
-		reactions, err := client.GetReactions(messageURL)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "エラー: リアクションの取得に失敗しました: %v\n", err)
-			os.Exit(1)
-		}
+		// 未リアクションのメンバーは nudge と同じ方法で集計
+		var nonReacted []slack.UserInfo
+		var reactions, rawReactions []slack.ReactionInfo
+		if missing {
+			nonReacted, err = collectNonReactedUsers(client, messageURL, filter)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, i18n.T("エラー: %v\n"), err)
+				os.Exit(1)
+			}
+		} else {
+			// リアクション一覧を取得
+			reactions, err = client.GetReactions(messageURL)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, i18n.T("エラー: リアクションの取得に失敗しました: %v\n"), err)
+				os.Exit(1)
+			}
+			// 構造化出力ではスキントーンを含む元のリアクション名を使うため保持しておく
+			rawReactions = reactions
 
-		// スキントーンなどの修飾子を除去してリアクションを統合
-		reactions = mergeReactions(reactions)
+			// スキントーンなどの修飾子を除去してリアクションを統合
+			reactions = mergeReactions(reactions)
 
-		// フィルタが指定されている場合は適用
-		if filter != "" {
-			reactions = filterReactions(reactions, filter)
+			// フィルタが指定されている場合は適用
+			if filter != "" {
+				reactions = filterReactions(reactions, filter)
+			}
 		}
 
 		// 出力先を決定
@@ -82,7 +142,7 @@ var getReactionsCmd = &cobra.Command{
 		if outputFile != "" {
 			file, err := os.Create(outputFile)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "エラー: ファイルの作成に失敗しました: %v\n", err)
+				fmt.Fprintf(os.Stderr, i18n.T("エラー: ファイルの作成に失敗しました: %v\n"), err)
 				os.Exit(1)
 			}
 			defer file.Close()
@@ -91,36 +151,149 @@ var getReactionsCmd = &cobra.Command{
 			output = os.Stdout
 		}
 
-		// リアクション別にユーザー一覧を出力
-		for _, reaction := range reactions {
-			if simple {
-				// シンプル形式：リアクション名のみ、改行区切りでユーザー一覧
-				fmt.Fprintf(output, ":%s:\n", reaction.Name)
-				for _, user := range reaction.Users {
-					if email {
-						fmt.Fprintln(output, user.Email)
-					} else {
-						fmt.Fprintln(output, user.Name)
-					}
+		if missing {
+			// 未リアクションのメンバーを出力
+			if format != "" {
+				// 構造化出力ではリアクション名を空にした行として出力
+				var rows []reactionRow
+				for _, user := range nonReacted {
+					rows = append(rows, newReactionRow("", "", user))
+				}
+				if err := writeReactionRows(output, rows, format); err != nil {
+					fmt.Fprintf(os.Stderr, i18n.T("エラー: 出力に失敗しました: %v\n"), err)
+					os.Exit(1)
 				}
-				fmt.Fprintln(output) // 空行を追加
 			} else {
-				// 通常形式：リアクション名と人数、インデント付き
-				fmt.Fprintf(output, ":%s: (%d人)\n", reaction.Name, len(reaction.Users))
-				for _, user := range reaction.Users {
-					if email {
-						fmt.Fprintf(output, "  - %s\n", user.Email)
-					} else {
-						fmt.Fprintf(output, "  - %s\n", user.Name)
-					}
+				label := i18n.T("未リアクション")
+				if filter != "" {
+					label = fmt.Sprintf(i18n.T("未リアクション :%s:"), strings.Trim(filter, ":"))
 				}
-				fmt.Fprintln(output) // 空行を追加
+				writeReactionUsers(output, label, nonReacted, email, simple)
+			}
+		} else if format != "" {
+			// ユーザー×リアクションごとに1行の構造化出力
+			if err := writeReactionRows(output, buildReactionRows(rawReactions, filter), format); err != nil {
+				fmt.Fprintf(os.Stderr, i18n.T("エラー: 出力に失敗しました: %v\n"), err)
+				os.Exit(1)
+			}
+		} else if byUser {
+			// ユーザー別にリアクション一覧を出力
+			for _, entry := range groupReactionsByUser(reactions, sortOrder) {
+				writeUserReactions(output, entry, email, simple)
+			}
+		} else {
+			// リアクション別にユーザー一覧を出力
+			for _, reaction := range sortReactions(reactions, sortOrder) {
+				writeReactionUsers(output, ":"+reaction.Name+":", reaction.Users, email, simple)
 			}
 		}
 
 		// ファイルに保存した場合のメッセージ
 		if outputFile != "" {
-			fmt.Fprintf(os.Stderr, "リアクション一覧を %s に保存しました\n", outputFile)
+			fmt.Fprintf(os.Stderr, i18n.T("リアクション一覧を %s に保存しました\n"), outputFile)
+		}
+	},
+}
+
+var reactionsNudgeCmd = &cobra.Command{
+	Use:   "nudge <message-url>",
+	Short: "未リアクションのメンバーにスレッドでリマインド",
+	Long: `指定したSlack投稿にリアクションしていないチャンネルメンバーを調べ、
+その投稿のスレッドにメンション付きで返信します。
+
+テンプレートでは以下のプレースホルダーが使用できます:
+  {mentions}  未リアクションのメンバーへのメンション
+  {emoji}     --emoji で指定したリアクション名
+  {count}     未リアクションのメンバー数
+
+メンション数が --batch-size を超える場合は、複数の返信に分割して投稿します。
+
+例:
+  slack-tool reactions nudge "https://workspace.slack.com/archives/C12345678/p1234567890123456" --emoji ok
+  slack-tool reactions nudge "https://workspace.slack.com/archives/C12345678/p1234567890123456" --emoji ok --dry-run
+  slack-tool reactions nudge "https://workspace.slack.com/archives/C12345678/p1234567890123456" --template "{mentions} 出欠の回答をお願いします！"`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		messageURL := args[0]
+
+		cfg, err := loadConfig(cmd, args...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("エラー: 設定の読み込みに失敗しました: %v\n"), err)
+			os.Exit(1)
+		}
+
+		client := slack.NewClient(cfg.SlackToken)
+
+		if err := client.TestConnection(); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("エラー: Slack APIへの接続に失敗しました: %v\n"), err)
+			os.Exit(1)
+		}
+
+		// オプションを取得
+		emoji, _ := cmd.Flags().GetString("emoji")
+		template, _ := cmd.Flags().GetString("template")
+		batchSize, _ := cmd.Flags().GetInt("batch-size")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		emoji = strings.Trim(emoji, ":")
+		if template == "" {
+			if emoji != "" {
+				template = i18n.T("{mentions}\nこちらの投稿に :{emoji}: でリアクションをお願いします！")
+			} else {
+				template = i18n.T("{mentions}\nこちらの投稿へのリアクションをお願いします！")
+			}
+		}
+		if batchSize <= 0 {
+			fmt.Fprint(os.Stderr, i18n.T("エラー: --batch-size には1以上の値を指定してください\n"))
+			os.Exit(1)
+		}
+
+		// 未リアクションのメンバーを取得
+		nonReacted, err := collectNonReactedUsers(client, messageURL, emoji)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("エラー: %v\n"), err)
+			os.Exit(1)
+		}
+
+		if len(nonReacted) == 0 {
+			fmt.Fprintln(os.Stderr, i18n.T("情報: 未リアクションのメンバーはいません。"))
+			return
+		}
+
+		// 返信先のスレッドを特定（スレッド内の投稿の場合は親スレッドに返信）
+		threadInfo, err := slack.ParseThreadURL(messageURL)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("エラー: %v\n"), err)
+			os.Exit(1)
+		}
+		msg, err := client.GetMessageInfo(threadInfo.ChannelID, threadInfo.Timestamp)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("エラー: メッセージ情報の取得に失敗しました: %v\n"), err)
+			os.Exit(1)
+		}
+		threadTimestamp := msg.Timestamp
+		if msg.ThreadTimestamp != "" {
+			threadTimestamp = msg.ThreadTimestamp
+		}
+
+		// メンションをバッチに分割して返信
+		messages := buildNudgeMessages(template, emoji, nonReacted, batchSize)
+		for i, text := range messages {
+			if dryRun {
+				fmt.Printf(i18n.T("--- 返信 %d/%d ---\n%s\n\n"), i+1, len(messages), text)
+				continue
+			}
+
+			if err := client.PostThreadReply(threadInfo.ChannelID, text, threadTimestamp); err != nil {
+				fmt.Fprintf(os.Stderr, i18n.T("エラー: スレッド返信の投稿に失敗しました: %v\n"), err)
+				os.Exit(1)
+			}
+		}
+
+		if dryRun {
+			fmt.Fprintf(os.Stderr, i18n.T("情報: ドライランのため投稿していません（対象: %d人）\n"), len(nonReacted))
+		} else {
+			fmt.Printf(i18n.T("%d人にリマインドを投稿しました\n"), len(nonReacted))
 		}
 	},
 }
@@ -128,46 +301,122 @@ var getReactionsCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(reactionsCmd)
 	getCmd.AddCommand(getReactionsCmd)
+	reactionsCmd.AddCommand(reactionsNudgeCmd)
 
 	// reactions コマンドのフラグ（省略形用）
 	reactionsCmd.Flags().StringP("filter", "f", "", "特定のリアクションのみをフィルタ（例: :参加します:）")
 	reactionsCmd.Flags().BoolP("email", "e", false, "ユーザー名の代わりにメールアドレスを出力")
 	reactionsCmd.Flags().StringP("output", "o", "", "結果をファイルに保存（例: reactions.txt）")
 	reactionsCmd.Flags().BoolP("simple", "s", false, "シンプル形式で出力（改行のみで区切り、Googleカレンダーなどにコピーしやすい）")
+	reactionsCmd.Flags().BoolP("missing", "m", false, "リアクションしていないチャンネルメンバーを出力（--filter 指定時はそのリアクションをしていないメンバー）")
+	reactionsCmd.Flags().String("format", "", "構造化形式で出力（csv / tsv / json / vcard / ics-attendees）")
+	reactionsCmd.Flags().String("sort", "slack", "リアクションの並び順（slack: Slack上の順序 / count: 人数順 / name: 名前順）")
+	reactionsCmd.Flags().Bool("by-user", false, "ユーザー別にリアクション一覧を出力")
+	reactionsCmd.Flags().String("search", "", "検索クエリに一致する投稿を対象に出欠表を出力（例: \"週次勉強会 in:#events\"）")
+	reactionsCmd.Flags().Int("search-limit", 100, "--search で対象とする投稿の最大数")
 
 	// get reactions コマンドのフラグ
 	getReactionsCmd.Flags().StringP("filter", "f", "", "特定のリアクションのみをフィルタ（例: :参加します:）")
 	getReactionsCmd.Flags().BoolP("email", "e", false, "ユーザー名の代わりにメールアドレスを出力")
 	getReactionsCmd.Flags().StringP("output", "o", "", "結果をファイルに保存（例: reactions.txt）")
 	getReactionsCmd.Flags().BoolP("simple", "s", false, "シンプル形式で出力（改行のみで区切り、Googleカレンダーなどにコピーしやすい）")
+	getReactionsCmd.Flags().BoolP("missing", "m", false, "リアクションしていないチャンネルメンバーを出力（--filter 指定時はそのリアクションをしていないメンバー）")
+	getReactionsCmd.Flags().String("format", "", "構造化形式で出力（csv / tsv / json / vcard / ics-attendees）")
+	getReactionsCmd.Flags().String("sort", "slack", "リアクションの並び順（slack: Slack上の順序 / count: 人数順 / name: 名前順）")
+	getReactionsCmd.Flags().Bool("by-user", false, "ユーザー別にリアクション一覧を出力")
+	getReactionsCmd.Flags().String("search", "", "検索クエリに一致する投稿を対象に出欠表を出力（例: \"週次勉強会 in:#events\"）")
+	getReactionsCmd.Flags().Int("search-limit", 100, "--search で対象とする投稿の最大数")
+
+	// reactions nudge コマンドのフラグ
+	reactionsNudgeCmd.Flags().StringP("emoji", "e", "", "対象のリアクション（例: ok）。省略時はいずれかのリアクションをしていないメンバーが対象")
+	reactionsNudgeCmd.Flags().StringP("template", "t", "", "返信メッセージのテンプレート（{mentions}, {emoji}, {count} が使用可能）")
+	reactionsNudgeCmd.Flags().IntP("batch-size", "b", 50, "1回の返信に含めるメンションの最大数")
+	reactionsNudgeCmd.Flags().BoolP("dry-run", "n", false, "投稿せずに返信内容を表示")
 }
 
-// mergeReactions merges reactions with skin tone modifiers into base reactions
+// mergeReactions merges reactions with skin tone modifiers into base reactions.
+// The order of first appearance on Slack is kept and users are deduplicated within each merged reaction.
 func mergeReactions(reactions []slack.ReactionInfo) []slack.ReactionInfo {
-	// 基本リアクション名をキーとして、ユーザーを統合
-	reactionMap := make(map[string][]slack.UserInfo)
+	// 基本リアクション名をキーとして、ユーザーを統合（出現順を保持）
+	var merged []slack.ReactionInfo
+	indexByName := make(map[string]int)
+	seenUsers := make(map[string]map[string]bool)
 
 	for _, reaction := range reactions {
 		baseName := normalizeReactionName(reaction.Name)
 
-		// 既存のユーザーリストに追加
-		if existingUsers, exists := reactionMap[baseName]; exists {
-			reactionMap[baseName] = append(existingUsers, reaction.Users...)
-		} else {
-			reactionMap[baseName] = reaction.Users
+		index, exists := indexByName[baseName]
+		if !exists {
+			index = len(merged)
+			indexByName[baseName] = index
+			seenUsers[baseName] = make(map[string]bool)
+			merged = append(merged, slack.ReactionInfo{Name: baseName})
+		}
+
+		// 同じユーザーが複数のスキントーンでリアクションしている場合は1回だけ数える
+		for _, user := range reaction.Users {
+			if seenUsers[baseName][user.ID] {
+				continue
+			}
+			seenUsers[baseName][user.ID] = true
+			merged[index].Users = append(merged[index].Users, user)
 		}
 	}
 
-	// マップからスライスに変換
-	var merged []slack.ReactionInfo
-	for baseName, users := range reactionMap {
-		merged = append(merged, slack.ReactionInfo{
-			Name:  baseName,
-			Users: users,
+	return merged
+}
+
+// sortReactions sorts reactions by the specified order (slack / count / name)
+func sortReactions(reactions []slack.ReactionInfo, order string) []slack.ReactionInfo {
+	switch order {
+	case "count":
+		// 人数の多い順（同数の場合はSlack上の順序）
+		sort.SliceStable(reactions, func(i, j int) bool {
+			return len(reactions[i].Users) > len(reactions[j].Users)
+		})
+	case "name":
+		sort.SliceStable(reactions, func(i, j int) bool {
+			return reactions[i].Name < reactions[j].Name
 		})
 	}
+	// "slack" の場合はSlack上の順序のまま
+	return reactions
+}
 
-	return merged
+// userReactions contains the reactions made by a single user
+type userReactions struct {
+	User      slack.UserInfo
+	Reactions []string
+}
+
+// groupReactionsByUser inverts reactions into a per-user view
+func groupReactionsByUser(reactions []slack.ReactionInfo, order string) []userReactions {
+	var grouped []userReactions
+	indexByUser := make(map[string]int)
+
+	for _, reaction := range reactions {
+		for _, user := range reaction.Users {
+			index, exists := indexByUser[user.ID]
+			if !exists {
+				index = len(grouped)
+				indexByUser[user.ID] = index
+				grouped = append(grouped, userReactions{User: user})
+			}
+			grouped[index].Reactions = append(grouped[index].Reactions, reaction.Name)
+		}
+	}
+
+	switch order {
+	case "count":
+		sort.SliceStable(grouped, func(i, j int) bool {
+			return len(grouped[i].Reactions) > len(grouped[j].Reactions)
+		})
+	case "name":
+		sort.SliceStable(grouped, func(i, j int) bool {
+			return grouped[i].User.Name < grouped[j].User.Name
+		})
+	}
+	return grouped
 }
 
 // normalizeReactionName removes skin tone and other modifiers from reaction names
@@ -212,3 +461,121 @@ func filterReactions(reactions []slack.ReactionInfo, filter string) []slack.Reac
 	}
 	return filtered
 }
+
+// collectNonReactedUsers gets channel members who have not reacted to the message (used by --missing and nudge)
+func collectNonReactedUsers(client *slack.Client, messageURL, filter string) ([]slack.UserInfo, error) {
+	reactions, err := client.GetReactions(messageURL)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("リアクションの取得に失敗しました: %v"), err)
+	}
+
+	// スキントーンなどの修飾子を除去してリアクションを統合
+	reactions = mergeReactions(reactions)
+	if filter != "" {
+		reactions = filterReactions(reactions, filter)
+	}
+
+	threadInfo, err := slack.ParseThreadURL(messageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := client.GetChannelMembers(threadInfo.ChannelID)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("チャンネルメンバーの取得に失敗しました: %v"), err)
+	}
+
+	return findNonReactedUsers(members, reactions), nil
+}
+
+// buildNudgeMessages renders the nudge template, splitting mentions into batches
+func buildNudgeMessages(template, emoji string, users []slack.UserInfo, batchSize int) []string {
+	var messages []string
+	for start := 0; start < len(users); start += batchSize {
+		end := start + batchSize
+		if end > len(users) {
+			end = len(users)
+		}
+
+		mentions := make([]string, 0, end-start)
+		for _, user := range users[start:end] {
+			mentions = append(mentions, fmt.Sprintf("<@%s>", user.ID))
+		}
+
+		text := strings.NewReplacer(
+			"{mentions}", strings.Join(mentions, " "),
+			"{emoji}", emoji,
+			"{count}", fmt.Sprintf("%d", len(users)),
+		).Replace(template)
+		messages = append(messages, text)
+	}
+	return messages
+}
+
+// findNonReactedUsers returns channel members who are not included in any of the reactions
+func findNonReactedUsers(members []slack.UserInfo, reactions []slack.ReactionInfo) []slack.UserInfo {
+	// リアクション済みのユーザーIDを集計
+	reacted := make(map[string]bool)
+	for _, reaction := range reactions {
+		for _, user := range reaction.Users {
+			reacted[user.ID] = true
+		}
+	}
+
+	var nonReacted []slack.UserInfo
+	for _, member := range members {
+		if !reacted[member.ID] {
+			nonReacted = append(nonReacted, member)
+		}
+	}
+	return nonReacted
+}
+
+// writeReactionUsers writes a labeled user list in normal or simple format
+func writeReactionUsers(output io.Writer, label string, users []slack.UserInfo, email, simple bool) {
+	if simple {
+		// シンプル形式：ラベルのみ、改行区切りでユーザー一覧
+		fmt.Fprintln(output, label)
+		for _, user := range users {
+			if email {
+				fmt.Fprintln(output, user.Email)
+			} else {
+				fmt.Fprintln(output, user.Name)
+			}
+		}
+	} else {
+		// 通常形式：ラベルと人数、インデント付き
+		fmt.Fprintf(output, i18n.T("%s (%d人)\n"), label, len(users))
+		for _, user := range users {
+			if email {
+				fmt.Fprintf(output, "  - %s\n", user.Email)
+			} else {
+				fmt.Fprintf(output, "  - %s\n", user.Name)
+			}
+		}
+	}
+	fmt.Fprintln(output) // 空行を追加
+}
+
+// writeUserReactions writes a user's reactions in normal or simple format
+func writeUserReactions(output io.Writer, entry userReactions, email, simple bool) {
+	label := entry.User.Name
+	if email {
+		label = entry.User.Email
+	}
+
+	if simple {
+		// シンプル形式：ユーザーのみ、改行区切りでリアクション一覧
+		fmt.Fprintln(output, label)
+		for _, name := range entry.Reactions {
+			fmt.Fprintf(output, ":%s:\n", name)
+		}
+	} else {
+		// 通常形式：ユーザーとリアクション数、インデント付き
+		fmt.Fprintf(output, i18n.T("%s (%d件)\n"), label, len(entry.Reactions))
+		for _, name := range entry.Reactions {
+			fmt.Fprintf(output, "  - :%s:\n", name)
+		}
+	}
+	fmt.Fprintln(output) // 空行を追加
+}
@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/shellme/slack-tool/internal/i18n"
+	"github.com/shellme/slack-tool/internal/slack"
+)
+
+// reactionExportFormats lists the formats supported by --format of the reactions command
+var reactionExportFormats = []string{"csv", "tsv", "json", "vcard", "ics-attendees"}
+
+// reactionRow is a single user × reaction row for structured export
+type reactionRow struct {
+	Reaction string `json:"reaction"` // 統合後のリアクション名（例: +1）
+	Emoji    string `json:"emoji"`    // スキントーンを含む元のリアクション名（例: +1::skin-tone-2:）
+	UserID   string `json:"user_id"`
+	Handle   string `json:"handle"`
+	RealName string `json:"real_name"`
+	Email    string `json:"email"`
+}
+
+// buildReactionRows builds export rows from raw (unmerged) reactions, keeping the original emoji name
+func buildReactionRows(rawReactions []slack.ReactionInfo, filter string) []reactionRow {
+	cleanFilter := strings.Trim(filter, ":")
+
+	var rows []reactionRow
+	for _, reaction := range rawReactions {
+		baseName := normalizeReactionName(reaction.Name)
+		if cleanFilter != "" && baseName != cleanFilter {
+			continue
+		}
+
+		for _, user := range reaction.Users {
+			rows = append(rows, newReactionRow(baseName, reaction.Name, user))
+		}
+	}
+	return rows
+}
+
+// newReactionRow creates an export row for a user
+func newReactionRow(reaction, emoji string, user slack.UserInfo) reactionRow {
+	return reactionRow{
+		Reaction: reaction,
+		Emoji:    emoji,
+		UserID:   user.ID,
+		Handle:   user.Name,
+		RealName: user.RealName,
+		Email:    user.Email,
+	}
+}
+
+// isReactionExportFormat reports whether the format is supported by writeReactionRows
+func isReactionExportFormat(format string) bool {
+	for _, f := range reactionExportFormats {
+		if strings.EqualFold(f, format) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeReactionRows writes export rows in the specified format
+func writeReactionRows(output io.Writer, rows []reactionRow, format string) error {
+	switch strings.ToLower(format) {
+	case "csv":
+		return writeReactionRowsDelimited(output, rows, ',')
+	case "tsv":
+		return writeReactionRowsDelimited(output, rows, '\t')
+	case "json":
+		encoder := json.NewEncoder(output)
+		encoder.SetIndent("", "  ")
+		if rows == nil {
+			rows = []reactionRow{}
+		}
+		return encoder.Encode(rows)
+	case "vcard":
+		return writeReactionRowsVCard(output, rows)
+	case "ics-attendees":
+		return writeReactionRowsICSAttendees(output, rows)
+	default:
+		return fmt.Errorf(i18n.T("サポートされていない出力形式です: %s（%s のいずれかを指定してください）"), format, strings.Join(reactionExportFormats, ", "))
+	}
+}
+
+// writeReactionRowsDelimited writes rows as CSV or TSV with a header line
+func writeReactionRowsDelimited(output io.Writer, rows []reactionRow, delimiter rune) error {
+	writer := csv.NewWriter(output)
+	writer.Comma = delimiter
+
+	if err := writer.Write([]string{"reaction", "emoji", "user_id", "handle", "real_name", "email"}); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := writer.Write([]string{row.Reaction, row.Emoji, row.UserID, row.Handle, row.RealName, row.Email}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeReactionRowsVCard writes one vCard per unique user
+func writeReactionRowsVCard(output io.Writer, rows []reactionRow) error {
+	for _, row := range uniqueReactionRowsByUser(rows) {
+		name := row.RealName
+		if name == "" {
+			name = row.Handle
+		}
+
+		lines := []string{
+			"BEGIN:VCARD",
+			"VERSION:3.0",
+			"FN:" + escapeVCardValue(name),
+			// N は vCard 3.0 で必須（姓・名の順序は判別できないため表示名全体を姓として出力）
+			"N:" + escapeVCardValue(name) + ";;;;",
+			"NICKNAME:" + escapeVCardValue(row.Handle),
+		}
+		if row.Email != "" {
+			lines = append(lines, "EMAIL;TYPE=INTERNET:"+escapeVCardValue(row.Email))
+		}
+		lines = append(lines, "X-SLACK-USER-ID:"+row.UserID, "END:VCARD")
+
+		// vCardの行区切りはCRLF（75オクテットを超える行は折り返す）
+		for _, line := range lines {
+			if _, err := io.WriteString(output, foldContentLine(line)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeReactionRowsICSAttendees writes ATTENDEE lines for an iCalendar event (users without email are skipped)
+func writeReactionRowsICSAttendees(output io.Writer, rows []reactionRow) error {
+	for _, row := range uniqueReactionRowsByUser(rows) {
+		if row.Email == "" {
+			continue
+		}
+
+		name := row.RealName
+		if name == "" {
+			name = row.Handle
+		}
+
+		line := fmt.Sprintf("ATTENDEE;CN=\"%s\";ROLE=REQ-PARTICIPANT;PARTSTAT=NEEDS-ACTION:mailto:%s",
+			escapeParamValue(name), row.Email)
+		if _, err := io.WriteString(output, foldContentLine(line)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uniqueReactionRowsByUser returns the first row for each user, preserving order
+func uniqueReactionRowsByUser(rows []reactionRow) []reactionRow {
+	seen := make(map[string]bool)
+	var unique []reactionRow
+	for _, row := range rows {
+		if seen[row.UserID] {
+			continue
+		}
+		seen[row.UserID] = true
+		unique = append(unique, row)
+	}
+	return unique
+}
+
+// escapeVCardValue escapes special characters in a vCard property value
+func escapeVCardValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\n", `\n`).Replace(value)
+}
+
+// escapeParamValue encodes a quoted parameter value with the caret encoding of RFC 6868
+// (^ -> ^^, newline -> ^n, " -> ^'), since quoted-string values cannot contain DQUOTE
+func escapeParamValue(value string) string {
+	value = strings.NewReplacer("^", "^^", "\r\n", "^n", "\n", "^n", `"`, "^'").Replace(value)
+	// その他の制御文字はパラメータ値に使用できないため除去
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 && r != '\t' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, value)
+}
+
+// foldContentLine terminates a content line with CRLF, folding it into lines of at most 75 octets
+// (continuation lines start with a space) without splitting a UTF-8 sequence (RFC 5545 3.1, RFC 2425 5.8.1)
+func foldContentLine(line string) string {
+	const maxOctets = 75
+
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := utf8.RuneLen(r)
+		if width+size > maxOctets {
+			b.WriteString("\r\n ")
+			width = 1 // 継続行の先頭の空白
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	b.WriteString("\r\n")
+	return b.String()
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestFoldContentLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"short", "BEGIN:VCARD"},
+		{"exactly 75 octets", strings.Repeat("a", 75)},
+		{"ascii", "ATTENDEE;CN=\"Taro Yamada\";ROLE=REQ-PARTICIPANT;PARTSTAT=NEEDS-ACTION:mailto:taro.yamada@example.com"},
+		{"multibyte", "FN:" + strings.Repeat("山田太郎", 20)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folded := foldContentLine(tt.line)
+			if !strings.HasSuffix(folded, "\r\n") {
+				t.Fatalf("folded line does not end with CRLF: %q", folded)
+			}
+
+			lines := strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n")
+			for i, line := range lines {
+				if len(line) > 75 {
+					t.Errorf("line %d is %d octets: %q", i, len(line), line)
+				}
+				if i > 0 && !strings.HasPrefix(line, " ") {
+					t.Errorf("continuation line %d does not start with a space: %q", i, line)
+				}
+				if !utf8.ValidString(line) {
+					t.Errorf("line %d splits a UTF-8 sequence: %q", i, line)
+				}
+			}
+
+			// 折り返しを解除すると元の行に戻る
+			if unfolded := strings.ReplaceAll(strings.TrimSuffix(folded, "\r\n"), "\r\n ", ""); unfolded != tt.line {
+				t.Errorf("unfolded = %q, want %q", unfolded, tt.line)
+			}
+		})
+	}
+}
+
+func TestEscapeParamValue(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"Taro Yamada", "Taro Yamada"},
+		{`Taro "Tom" Yamada`, "Taro ^'Tom^' Yamada"},
+		{"a^b", "a^^b"},
+		{"line1\nline2", "line1^nline2"},
+		{"bell\x07", "bell"},
+	}
+
+	for _, tt := range tests {
+		if got := escapeParamValue(tt.value); got != tt.want {
+			t.Errorf("escapeParamValue(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestWriteReactionRowsICSAttendees(t *testing.T) {
+	rows := []reactionRow{
+		{Reaction: "ok", UserID: "U1", Handle: "taro", RealName: `Taro "Tom" Yamada`, Email: "taro.yamada@example.com"},
+		{Reaction: "+1", UserID: "U1", Handle: "taro", RealName: `Taro "Tom" Yamada`, Email: "taro.yamada@example.com"},
+		{Reaction: "ok", UserID: "U2", Handle: "hanako"},
+	}
+
+	var buf bytes.Buffer
+	if err := writeReactionRowsICSAttendees(&buf, rows); err != nil {
+		t.Fatal(err)
+	}
+
+	unfolded := strings.ReplaceAll(buf.String(), "\r\n ", "")
+	want := "ATTENDEE;CN=\"Taro ^'Tom^' Yamada\";ROLE=REQ-PARTICIPANT;PARTSTAT=NEEDS-ACTION:mailto:taro.yamada@example.com\r\n"
+	if unfolded != want {
+		t.Errorf("got %q, want %q", unfolded, want)
+	}
+	for _, line := range strings.Split(buf.String(), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("unfolded line of %d octets: %q", len(line), line)
+		}
+	}
+}
+
+func TestWriteReactionRowsVCard(t *testing.T) {
+	rows := []reactionRow{{Reaction: "ok", UserID: "U1", Handle: "taro", RealName: "Yamada, Taro", Email: "taro@example.com"}}
+
+	var buf bytes.Buffer
+	if err := writeReactionRowsVCard(&buf, rows); err != nil {
+		t.Fatal(err)
+	}
+
+	want := strings.Join([]string{
+		"BEGIN:VCARD",
+		"VERSION:3.0",
+		`FN:Yamada\, Taro`,
+		`N:Yamada\, Taro;;;;`,
+		"NICKNAME:taro",
+		"EMAIL;TYPE=INTERNET:taro@example.com",
+		"X-SLACK-USER-ID:U1",
+		"END:VCARD",
+	}, "\r\n") + "\r\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,284 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/shellme/slack-tool/internal/i18n"
+	"github.com/shellme/slack-tool/internal/slack"
+	"github.com/spf13/cobra"
+)
+
+// reactionMatrix is a user × message attendance matrix built from reactions
+type reactionMatrix struct {
+	Messages []matrixMessage `json:"messages"`
+	Users    []matrixUserRow `json:"users"`
+}
+
+// matrixMessage is a column of the attendance matrix
+type matrixMessage struct {
+	Label     string `json:"label"`
+	URL       string `json:"url"`
+	Timestamp string `json:"timestamp"`
+}
+
+// matrixUserRow is a row of the attendance matrix
+type matrixUserRow struct {
+	User      slack.UserInfo `json:"user"`
+	Reactions [][]string     `json:"reactions"` // 投稿ごとのリアクション名（列の順序と対応）
+	Total     int            `json:"total"`     // リアクションした投稿の数
+}
+
+// runReactionMatrix fetches reactions for several messages and writes an attendance matrix
+func runReactionMatrix(cmd *cobra.Command, client *slack.Client, urls []string, search string, loc *time.Location) {
+	filter, _ := cmd.Flags().GetString("filter")
+	email, _ := cmd.Flags().GetBool("email")
+	outputFile, _ := cmd.Flags().GetString("output")
+	format, _ := cmd.Flags().GetString("format")
+	sortOrder, _ := cmd.Flags().GetString("sort")
+	searchLimit, _ := cmd.Flags().GetInt("search-limit")
+
+	// 出力形式を検証
+	switch strings.ToLower(format) {
+	case "", "table", "csv", "tsv", "json":
+	default:
+		fmt.Fprintf(os.Stderr, i18n.T("エラー: 出欠表ではサポートされていない出力形式です: %s（table / csv / tsv / json のいずれかを指定してください）\n"), format)
+		os.Exit(1)
+	}
+
+	// 検索クエリに一致する投稿を追加
+	if search != "" {
+		permalinks, err := client.SearchMessagePermalinks(search, searchLimit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("エラー: 投稿の検索に失敗しました: %v\n"), err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, i18n.T("情報: 検索に一致した投稿: %d件\n"), len(permalinks))
+		urls = append(urls, permalinks...)
+	}
+
+	if len(urls) == 0 {
+		fmt.Fprint(os.Stderr, i18n.T("エラー: 対象の投稿がありません\n"))
+		os.Exit(1)
+	}
+
+	// 投稿ごとにリアクションを取得
+	reactionsByMessage := make([][]slack.ReactionInfo, 0, len(urls))
+	var messages []matrixMessage
+	for _, url := range urls {
+		reactions, err := client.GetReactions(url)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("エラー: リアクションの取得に失敗しました (%s): %v\n"), url, err)
+			os.Exit(1)
+		}
+
+		// スキントーンなどの修飾子を除去してリアクションを統合
+		reactions = mergeReactions(reactions)
+		if filter != "" {
+			reactions = filterReactions(reactions, filter)
+		}
+		reactionsByMessage = append(reactionsByMessage, reactions)
+
+		message, err := newMatrixMessage(url, loc)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("エラー: %v\n"), err)
+			os.Exit(1)
+		}
+		messages = append(messages, message)
+	}
+
+	matrix := buildReactionMatrix(messages, reactionsByMessage, sortOrder)
+
+	// 出力先を決定
+	var output *os.File
+	if outputFile != "" {
+		file, err := os.Create(outputFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("エラー: ファイルの作成に失敗しました: %v\n"), err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		output = file
+	} else {
+		output = os.Stdout
+	}
+
+	if err := writeReactionMatrix(output, matrix, format, email); err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("エラー: 出力に失敗しました: %v\n"), err)
+		os.Exit(1)
+	}
+
+	// ファイルに保存した場合のメッセージ
+	if outputFile != "" {
+		fmt.Fprintf(os.Stderr, i18n.T("出欠表を %s に保存しました\n"), outputFile)
+	}
+}
+
+// newMatrixMessage creates a matrix column labeled with the message date in the given time zone
+func newMatrixMessage(url string, loc *time.Location) (matrixMessage, error) {
+	threadInfo, err := slack.ParseThreadURL(url)
+	if err != nil {
+		return matrixMessage{}, err
+	}
+
+	seconds, err := strconv.ParseInt(strings.Split(threadInfo.Timestamp, ".")[0], 10, 64)
+	if err != nil {
+		return matrixMessage{}, fmt.Errorf(i18n.T("無効なタイムスタンプです: %s"), threadInfo.Timestamp)
+	}
+
+	return matrixMessage{
+		Label:     time.Unix(seconds, 0).In(loc).Format("2006-01-02"),
+		URL:       url,
+		Timestamp: threadInfo.Timestamp,
+	}, nil
+}
+
+// buildReactionMatrix builds a user × message matrix from per-message reactions
+func buildReactionMatrix(messages []matrixMessage, reactionsByMessage [][]slack.ReactionInfo, order string) reactionMatrix {
+	// 同じ日付の投稿が複数ある場合はラベルに連番を付与
+	labelCount := make(map[string]int)
+	for i := range messages {
+		labelCount[messages[i].Label]++
+		if n := labelCount[messages[i].Label]; n > 1 {
+			messages[i].Label = fmt.Sprintf("%s (%d)", messages[i].Label, n)
+		}
+	}
+
+	var rows []matrixUserRow
+	indexByUser := make(map[string]int)
+
+	for column, reactions := range reactionsByMessage {
+		for _, reaction := range reactions {
+			for _, user := range reaction.Users {
+				index, exists := indexByUser[user.ID]
+				if !exists {
+					index = len(rows)
+					indexByUser[user.ID] = index
+					rows = append(rows, matrixUserRow{
+						User:      user,
+						Reactions: make([][]string, len(messages)),
+					})
+				}
+				rows[index].Reactions[column] = append(rows[index].Reactions[column], reaction.Name)
+			}
+		}
+	}
+
+	// ユーザーごとの合計（リアクションした投稿の数）を集計
+	for i := range rows {
+		for _, cell := range rows[i].Reactions {
+			if len(cell) > 0 {
+				rows[i].Total++
+			}
+		}
+	}
+
+	switch order {
+	case "count":
+		sort.SliceStable(rows, func(i, j int) bool {
+			return rows[i].Total > rows[j].Total
+		})
+	case "name":
+		sort.SliceStable(rows, func(i, j int) bool {
+			return rows[i].User.Name < rows[j].User.Name
+		})
+	}
+
+	return reactionMatrix{Messages: messages, Users: rows}
+}
+
+// writeReactionMatrix writes the matrix as a table, CSV, TSV or JSON
+func writeReactionMatrix(output io.Writer, matrix reactionMatrix, format string, email bool) error {
+	switch strings.ToLower(format) {
+	case "", "table":
+		return writeReactionMatrixTable(output, matrix, email)
+	case "csv":
+		return writeReactionMatrixDelimited(output, matrix, ',')
+	case "tsv":
+		return writeReactionMatrixDelimited(output, matrix, '\t')
+	case "json":
+		encoder := json.NewEncoder(output)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(matrix)
+	default:
+		return fmt.Errorf(i18n.T("出欠表ではサポートされていない出力形式です: %s（table / csv / tsv / json のいずれかを指定してください）"), format)
+	}
+}
+
+// writeReactionMatrixTable writes the matrix as an aligned text table
+func writeReactionMatrixTable(output io.Writer, matrix reactionMatrix, email bool) error {
+	writer := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+
+	header := []string{i18n.T("ユーザー")}
+	for _, message := range matrix.Messages {
+		header = append(header, message.Label)
+	}
+	header = append(header, i18n.T("合計"))
+	fmt.Fprintln(writer, strings.Join(header, "\t"))
+
+	for _, row := range matrix.Users {
+		label := row.User.Name
+		if email {
+			label = row.User.Email
+		}
+
+		line := []string{label}
+		for _, cell := range row.Reactions {
+			if len(cell) == 0 {
+				line = append(line, "-")
+			} else {
+				line = append(line, formatMatrixCell(cell))
+			}
+		}
+		line = append(line, strconv.Itoa(row.Total))
+		fmt.Fprintln(writer, strings.Join(line, "\t"))
+	}
+
+	return writer.Flush()
+}
+
+// writeReactionMatrixDelimited writes the matrix as CSV or TSV with a header line
+func writeReactionMatrixDelimited(output io.Writer, matrix reactionMatrix, delimiter rune) error {
+	writer := csv.NewWriter(output)
+	writer.Comma = delimiter
+
+	header := []string{"user_id", "handle", "real_name", "email"}
+	for _, message := range matrix.Messages {
+		header = append(header, message.Label)
+	}
+	header = append(header, "total")
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range matrix.Users {
+		record := []string{row.User.ID, row.User.Name, row.User.RealName, row.User.Email}
+		for _, cell := range row.Reactions {
+			record = append(record, formatMatrixCell(cell))
+		}
+		record = append(record, strconv.Itoa(row.Total))
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// formatMatrixCell formats reaction names in a matrix cell (e.g. ":ok: :tada:")
+func formatMatrixCell(names []string) string {
+	formatted := make([]string, 0, len(names))
+	for _, name := range names {
+		formatted = append(formatted, ":"+name+":")
+	}
+	return strings.Join(formatted, " ")
+}
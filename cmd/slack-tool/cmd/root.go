@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/shellme/slack-tool/internal/config"
+	"github.com/shellme/slack-tool/internal/i18n"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // ビルド時に設定される変数
@@ -36,8 +39,16 @@ Slackへの投稿やその他の操作をサポートします。
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
+	// 表示言語を決定（設定ファイルの language > LC_ALL / LC_MESSAGES / LANG > 日本語）
+	language := ""
+	if cfg, err := config.NewConfigManager().Load(); err == nil {
+		language = cfg.Language
+	}
+	i18n.SetLanguage(i18n.Detect(language))
+	localizeCommand(rootCmd)
+
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "エラーが発生しました: %v\n", err)
+		fmt.Fprintf(os.Stderr, i18n.T("エラーが発生しました: %v\n"), err)
 		os.Exit(1)
 	}
 }
@@ -45,4 +56,24 @@ func Execute() {
 func init() {
 	// バージョン表示のカスタマイズ
 	rootCmd.SetVersionTemplate(fmt.Sprintf("slack-tool version %s\ncommit: %s\nbuilt: %s\n", version, commit, date))
+
+	rootCmd.PersistentFlags().String("token", "", "使用するSlack APIトークン（他のトークンの指定より優先。プロセス一覧に表示されるため --token-fd や環境変数を推奨）")
+	rootCmd.PersistentFlags().Int("token-fd", -1, "トークンを読み込むファイルディスクリプタの番号（例: --token-fd 3 3< token.txt）")
+	rootCmd.PersistentFlags().String("profile", "", "使用するプロファイル名（省略時はURLのホスト・ワークスペースIDから自動選択、なければ current_profile）")
+}
+
+// localizeCommand translates the help texts of a command and its subcommands
+func localizeCommand(cmd *cobra.Command) {
+	cmd.Short = i18n.T(cmd.Short)
+	cmd.Long = i18n.T(cmd.Long)
+
+	translateUsage := func(flag *pflag.Flag) {
+		flag.Usage = i18n.T(flag.Usage)
+	}
+	cmd.LocalFlags().VisitAll(translateUsage)
+	cmd.PersistentFlags().VisitAll(translateUsage)
+
+	for _, child := range cmd.Commands() {
+		localizeCommand(child)
+	}
 }
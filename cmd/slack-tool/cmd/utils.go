@@ -1,112 +1,546 @@
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/shellme/slack-tool/internal/config"
+	"github.com/shellme/slack-tool/internal/i18n"
+	"github.com/shellme/slack-tool/internal/slack"
+	"github.com/spf13/cobra"
 )
 
-// saveToFile saves formatted content to a file
+// saveToFile saves rendered content to a file
 func saveToFile(content, filename, format string) error {
 	// ファイル名が指定されていない場合はデフォルト名を生成
 	if filename == "" {
-		timestamp := time.Now().Format("20060102_150405")
-		filename = fmt.Sprintf("slack_content_%s.md", timestamp)
+		filename = defaultOutputFilename(format)
 	}
 
-	// ファイル拡張子の取得（自動判定用）
-	ext := strings.ToLower(filepath.Ext(filename))
 	// ファイル拡張子が指定されていない場合は、formatの指定に応じて補完
-	if ext == "" {
-		if strings.EqualFold(format, "markdown") || strings.EqualFold(format, "md") {
-			filename += ".md"
-			ext = ".md"
-		} else {
-			// デフォルトはプレーンテキスト
-			filename += ".txt"
-			ext = ".txt"
-		}
+	if filepath.Ext(filename) == "" {
+		filename += outputExtension(format)
 	}
 
 	// ディレクトリが存在しない場合は作成
 	dir := filepath.Dir(filename)
 	if dir != "." {
 		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf("ディレクトリの作成に失敗しました: %v", err)
+			return fmt.Errorf(i18n.T("ディレクトリの作成に失敗しました: %v"), err)
 		}
 	}
 
-	// 形式に応じてコンテンツを変換
-	// 優先度: 明示的なformat指定 > 出力ファイル拡張子 > デフォルト(text)
-	var finalContent string
-	effectiveFormat := strings.ToLower(strings.TrimSpace(format))
-	if effectiveFormat == "" {
-		switch ext {
-		case ".md", ".markdown":
-			effectiveFormat = "markdown"
-		default:
-			effectiveFormat = "text"
+	// ファイルに書き込み
+	err := os.WriteFile(filename, []byte(content), 0644)
+	if err != nil {
+		return fmt.Errorf(i18n.T("ファイルの書き込みに失敗しました: %v"), err)
+	}
+
+	return nil
+}
+
+// defaultOutputFilename returns a timestamped file name for an output format
+func defaultOutputFilename(format string) string {
+	timestamp := time.Now().Format("20060102_150405")
+	return fmt.Sprintf("slack_content_%s%s", timestamp, outputExtension(format))
+}
+
+// chunkFilename returns the file name for a numbered part, e.g. channel.md → channel_001.md
+func chunkFilename(filename, format string, part int) string {
+	if filename == "" {
+		filename = defaultOutputFilename(format)
+	}
+	ext := filepath.Ext(filename)
+	if ext == "" {
+		ext = outputExtension(format)
+	}
+	return fmt.Sprintf("%s_%03d%s", strings.TrimSuffix(filename, filepath.Ext(filename)), part, ext)
+}
+
+// outputExtensions maps output formats to file extensions
+var outputExtensions = map[string]string{
+	"text":     ".txt",
+	"markdown": ".md",
+	"json":     ".json",
+	"jsonl":    ".jsonl",
+	"csv":      ".csv",
+	"html":     ".html",
+}
+
+// outputExtension returns the file extension for an output format (default: .txt)
+func outputExtension(format string) string {
+	if ext, exists := outputExtensions[normalizeOutputFormat(format)]; exists {
+		return ext
+	}
+	return ".txt"
+}
+
+// normalizeOutputFormat resolves format aliases such as "md" and "ndjson"
+func normalizeOutputFormat(format string) string {
+	format = strings.ToLower(strings.TrimSpace(format))
+	switch format {
+	case "md":
+		return "markdown"
+	case "txt":
+		return "text"
+	case "ndjson":
+		return "jsonl"
+	case "htm":
+		return "html"
+	}
+	return format
+}
+
+// resolveOutputFormat determines the output format.
+// 優先度: 明示的なformat指定 > 出力ファイル拡張子 > デフォルト(text)
+func resolveOutputFormat(format, outputFile string) string {
+	if strings.TrimSpace(format) != "" {
+		return normalizeOutputFormat(format)
+	}
+
+	ext := strings.ToLower(filepath.Ext(outputFile))
+	for name, formatExt := range outputExtensions {
+		if ext == formatExt {
+			return name
 		}
 	}
+	switch ext {
+	case ".markdown":
+		return "markdown"
+	case ".ndjson":
+		return "jsonl"
+	case ".htm":
+		return "html"
+	}
+	return "text"
+}
 
-	switch effectiveFormat {
-	case "markdown", "md":
-		finalContent = convertToMarkdown(content)
-	default:
-		finalContent = content
+// loadTemplateRenderer loads an output template from a file path, the templates directory or the built-in templates
+func loadTemplateRenderer(nameOrPath string) (*slack.TemplateRenderer, error) {
+	// ファイルパスとして存在する場合はそのまま使用
+	if data, err := os.ReadFile(nameOrPath); err == nil {
+		return slack.NewTemplateRenderer(filepath.Base(nameOrPath), string(data))
 	}
 
-	// ファイルに書き込み
-	err := os.WriteFile(filename, []byte(finalContent), 0644)
+	// 設定ディレクトリの templates/<名前>.tmpl（同名の組み込みテンプレートより優先）
+	name := strings.TrimSuffix(nameOrPath, slack.TemplateExtension)
+	templatePath := filepath.Join(config.NewConfigManager().GetTemplatesDir(), name+slack.TemplateExtension)
+	if data, err := os.ReadFile(templatePath); err == nil {
+		return slack.NewTemplateRenderer(name, string(data))
+	}
+
+	// 組み込みテンプレート
+	if text, exists := slack.BuiltinTemplate(name); exists {
+		return slack.NewTemplateRenderer(name, text)
+	}
+
+	return nil, fmt.Errorf(i18n.T("テンプレートが見つかりません: %s"), nameOrPath)
+}
+
+// outputRenderer returns the renderer for --template or --format
+func outputRenderer(templateName, format string) (slack.Renderer, error) {
+	if templateName == "" {
+		return slack.NewRenderer(format)
+	}
+
+	renderer, err := loadTemplateRenderer(templateName)
 	if err != nil {
-		return fmt.Errorf("ファイルの書き込みに失敗しました: %v", err)
+		return nil, err
 	}
+	return renderer, nil
+}
 
-	return nil
+// messageFilterFromFlags builds a message filter from --from, --exclude-user, --grep, --min-replies,
+// --has-files, --has-reaction, --threads-only, --no-threads and --keep-thread
+func messageFilterFromFlags(cmd *cobra.Command) (slack.MessageFilter, error) {
+	var filter slack.MessageFilter
+	filter.From, _ = cmd.Flags().GetStringSlice("from")
+	filter.ExcludeUsers, _ = cmd.Flags().GetStringSlice("exclude-user")
+	filter.MinReplies, _ = cmd.Flags().GetInt("min-replies")
+	filter.HasFiles, _ = cmd.Flags().GetBool("has-files")
+	filter.HasReactions, _ = cmd.Flags().GetStringSlice("has-reaction")
+	filter.ThreadsOnly, _ = cmd.Flags().GetBool("threads-only")
+	filter.NoThreads, _ = cmd.Flags().GetBool("no-threads")
+	filter.KeepThreads, _ = cmd.Flags().GetBool("keep-thread")
+
+	if filter.ThreadsOnly && filter.NoThreads {
+		return filter, errors.New(i18n.T("--threads-only と --no-threads は同時に指定できません"))
+	}
+	if filter.MinReplies < 0 {
+		return filter, errors.New(i18n.T("--min-replies には0以上の数値を指定してください"))
+	}
+	if grep, _ := cmd.Flags().GetString("grep"); grep != "" {
+		re, err := regexp.Compile(grep)
+		if err != nil {
+			return filter, fmt.Errorf(i18n.T("--grep の正規表現が不正です: %v"), err)
+		}
+		filter.Grep = re
+	}
+	return filter, nil
 }
 
-// convertToMarkdown converts plain text content to markdown format
-func convertToMarkdown(content string) string {
-	// Markdownファイルでもプレーンテキストと同じ形式を維持
-	// ヘッダーをMarkdownの見出しに変換し、取得日時も含める
-	lines := strings.Split(content, "\n")
-	var result []string
-
-	for _, line := range lines {
-		// ヘッダー行をMarkdownの見出しに変換（取得日時も含める）
-		if strings.Contains(line, "--- Slack") && strings.Contains(line, "の内容") {
-			// 取得日時を抽出
-			dateMatch := regexp.MustCompile(`\((\d{4}/\d{2}/\d{2} 取得)\)`).FindStringSubmatch(line)
-			if len(dateMatch) > 1 {
-				if strings.Contains(line, "スレッド") {
-					result = append(result, fmt.Sprintf("# Slackスレッドの内容 (%s)", dateMatch[1]))
-				} else if strings.Contains(line, "チャンネル") {
-					result = append(result, fmt.Sprintf("# Slackチャンネルの内容 (%s)", dateMatch[1]))
-				} else {
-					result = append(result, fmt.Sprintf("# Slackの内容 (%s)", dateMatch[1]))
-				}
+// subtypeOptionsFromFlags builds subtype options from --include-events and --exclude-events
+func subtypeOptionsFromFlags(cmd *cobra.Command) (slack.SubtypeOptions, error) {
+	opts := slack.DefaultSubtypeOptions()
+
+	includes, _ := cmd.Flags().GetStringSlice("include-events")
+	for _, category := range includes {
+		if err := opts.ApplySubtypeCategory(strings.TrimSpace(category), true); err != nil {
+			return opts, err
+		}
+	}
+
+	excludes, _ := cmd.Flags().GetStringSlice("exclude-events")
+	for _, category := range excludes {
+		if err := opts.ApplySubtypeCategory(strings.TrimSpace(category), false); err != nil {
+			return opts, err
+		}
+	}
+
+	return opts, nil
+}
+
+// resolveLocation resolves the time zone from --tz (if the command has it), the config, or the default
+func resolveLocation(cmd *cobra.Command, cfg *config.Config) (*time.Location, error) {
+	name := cfg.Timezone
+	if cmd.Flags().Lookup("tz") != nil {
+		if tz, _ := cmd.Flags().GetString("tz"); tz != "" {
+			name = tz
+		}
+	}
+
+	if name == "" {
+		return slack.DefaultLocation(), nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("無効なタイムゾーンです: %s"), name)
+	}
+	return loc, nil
+}
+
+// resolveTimeOptions builds time options from --tz, --time-format, --author-tz and the config
+func resolveTimeOptions(cmd *cobra.Command, cfg *config.Config) (slack.TimeOptions, error) {
+	loc, err := resolveLocation(cmd, cfg)
+	if err != nil {
+		return slack.TimeOptions{}, err
+	}
+
+	timeFormat, _ := cmd.Flags().GetString("time-format")
+	useAuthorTZ, _ := cmd.Flags().GetBool("author-tz")
+
+	// よく使う形式は名前でも指定可能
+	switch strings.ToLower(timeFormat) {
+	case "":
+		timeFormat = slack.DefaultTimeFormat
+	case "rfc3339", "iso8601":
+		timeFormat = time.RFC3339
+	case "short":
+		timeFormat = "01/02 15:04"
+	}
+
+	return slack.TimeOptions{
+		Location:          loc,
+		Format:            timeFormat,
+		UseAuthorTimezone: useAuthorTZ,
+	}, nil
+}
+
+// tokenBudgetOptions holds the --max-tokens, --trim and --chunk-overlap settings
+type tokenBudgetOptions struct {
+	MaxTokens int
+	Trim      slack.TrimStrategy
+	Overlap   int
+}
+
+// tokenBudgetFromFlags reads --max-tokens, --trim and --chunk-overlap
+func tokenBudgetFromFlags(cmd *cobra.Command) (tokenBudgetOptions, error) {
+	maxTokens, _ := cmd.Flags().GetInt("max-tokens")
+	trim, _ := cmd.Flags().GetString("trim")
+	overlap, _ := cmd.Flags().GetInt("chunk-overlap")
+
+	if maxTokens < 0 {
+		return tokenBudgetOptions{}, errors.New(i18n.T("--max-tokens には0以上の数値を指定してください"))
+	}
+	if overlap < 0 {
+		return tokenBudgetOptions{}, errors.New(i18n.T("--chunk-overlap には0以上の数値を指定してください"))
+	}
+
+	opts := tokenBudgetOptions{MaxTokens: maxTokens, Overlap: overlap}
+	if trim != "" {
+		if maxTokens == 0 {
+			return opts, errors.New(i18n.T("--trim は --max-tokens と同時に指定してください"))
+		}
+		strategy, err := slack.ParseTrimStrategy(trim)
+		if err != nil {
+			return opts, err
+		}
+		opts.Trim = strategy
+	}
+	return opts, nil
+}
+
+// outputDocument renders a document and writes it to a file or stdout.
+// With --max-tokens the document is trimmed, or split into numbered files when it does not fit.
+// savedMessage is printed after saving to a file and takes the file name.
+func outputDocument(doc *slack.Document, renderer slack.Renderer, budget tokenBudgetOptions, outputFile, format, savedMessage string) error {
+	parts := []*slack.Document{doc}
+	if budget.MaxTokens > 0 {
+		var err error
+		if budget.Trim != "" {
+			var removed int
+			doc, removed, err = slack.TrimDocument(doc, renderer, budget.MaxTokens, budget.Trim)
+			if removed > 0 {
+				fmt.Fprintf(os.Stderr, i18n.T("情報: トークン上限に収めるため %d件のメッセージを省略しました\n"), removed)
+			}
+			parts = []*slack.Document{doc}
+		} else {
+			parts, err = slack.SplitDocument(doc, renderer, budget.MaxTokens, budget.Overlap)
+		}
+		if err != nil {
+			return fmt.Errorf(i18n.T("メッセージの整形に失敗しました: %v"), err)
+		}
+	}
+
+	for _, part := range parts {
+		content, tokens, err := slack.RenderTokens(renderer, part)
+		if err != nil {
+			return fmt.Errorf(i18n.T("メッセージの整形に失敗しました: %v"), err)
+		}
+
+		// 分割した場合は連番のファイルに保存
+		filename := outputFile
+		if len(parts) > 1 {
+			filename = chunkFilename(outputFile, format, part.Part)
+		}
+
+		if filename != "" {
+			if err := saveToFile(content, filename, format); err != nil {
+				return fmt.Errorf(i18n.T("ファイルの保存に失敗しました: %v"), err)
+			}
+			if len(parts) > 1 {
+				fmt.Printf(i18n.T("パート %d/%d: %s（約%dトークン）\n"), part.Part, part.Parts, filename, tokens)
 			} else {
-				if strings.Contains(line, "スレッド") {
-					result = append(result, "# Slackスレッドの内容")
-				} else if strings.Contains(line, "チャンネル") {
-					result = append(result, "# Slackチャンネルの内容")
-				} else {
-					result = append(result, "# Slackの内容")
-				}
+				fmt.Printf(savedMessage, filename)
+			}
+		} else {
+			fmt.Print(content)
+		}
+
+		// トークン数の集計（標準出力の内容を汚さないように標準エラー出力へ）
+		if budget.MaxTokens > 0 {
+			if len(parts) == 1 {
+				fmt.Fprintf(os.Stderr, i18n.T("情報: 約%dトークン（上限: %d）\n"), tokens, budget.MaxTokens)
 			}
+			if tokens > budget.MaxTokens {
+				fmt.Fprintf(os.Stderr, i18n.T("警告: 上限（%dトークン）を超えています。1つのスレッドが上限より大きい可能性があります\n"), budget.MaxTokens)
+			}
+		}
+	}
+	return nil
+}
+
+// anonymizerFromFlags creates an anonymizer for --anonymize / --anonymize-map (nil when neither is set).
+// The custom patterns come from the config file, and an existing mapping file is loaded so pseudonyms stay the same across runs.
+func anonymizerFromFlags(cmd *cobra.Command, cfg *config.Config) (*slack.Anonymizer, error) {
+	anonymize, _ := cmd.Flags().GetBool("anonymize")
+	mappingFile, _ := cmd.Flags().GetString("anonymize-map")
+	if !anonymize && mappingFile == "" {
+		return nil, nil
+	}
+
+	anonymizer, err := slack.NewAnonymizer(cfg.AnonymizePatterns)
+	if err != nil {
+		return nil, err
+	}
+	if mappingFile != "" {
+		mapping, err := loadAnonymizeMapping(mappingFile)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		anonymizer.LoadMapping(mapping)
+	}
+	return anonymizer, nil
+}
+
+// loadAnonymizeMapping reads a mapping file written by --anonymize-map
+func loadAnonymizeMapping(filename string) (slack.AnonymizeMapping, error) {
+	var mapping slack.AnonymizeMapping
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return mapping, err
+	}
+	if err := json.Unmarshal(data, &mapping); err != nil {
+		return mapping, fmt.Errorf(i18n.T("対応表の解析に失敗しました: %v"), err)
+	}
+	return mapping, nil
+}
+
+// saveAnonymizeMapping writes the pseudonyms and masked values to the --anonymize-map file, if set
+func saveAnonymizeMapping(cmd *cobra.Command, anonymizer *slack.Anonymizer) error {
+	mappingFile, _ := cmd.Flags().GetString("anonymize-map")
+	if anonymizer == nil || mappingFile == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(anonymizer.Mapping(), "", "  ")
+	if err != nil {
+		return err
+	}
+	// 元の名前・連絡先を含むため所有者のみ読み書き可能にする
+	if err := os.WriteFile(mappingFile, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf(i18n.T("対応表の保存に失敗しました: %v"), err)
+	}
+	fmt.Fprintf(os.Stderr, i18n.T("情報: 匿名化の対応表を %s に保存しました\n"), mappingFile)
+	return nil
+}
+
+// addStatsFromFlags adds the statistics block to the document when --stats is set
+func addStatsFromFlags(cmd *cobra.Command, doc *slack.Document) {
+	if stats, _ := cmd.Flags().GetBool("stats"); !stats {
+		return
+	}
+	emojis, _ := cmd.Flags().GetStringSlice("resolved-emoji")
+	doc.Stats = slack.ComputeStats(doc, emojis)
+}
+
+// timeRangeFromFlags resolves --oldest/--since, --latest/--until, --on, --week and --month to Slack timestamps in loc.
+// --on / --week / --month set both ends and cannot be combined with each other or with the other flags.
+func timeRangeFromFlags(cmd *cobra.Command, loc *time.Location) (string, string, error) {
+	oldest, _ := cmd.Flags().GetString("oldest")
+	latest, _ := cmd.Flags().GetString("latest")
+	since, _ := cmd.Flags().GetString("since")
+	until, _ := cmd.Flags().GetString("until")
+	if oldest != "" && since != "" {
+		return "", "", errors.New(i18n.T("--oldest と --since は同時に指定できません"))
+	}
+	if latest != "" && until != "" {
+		return "", "", errors.New(i18n.T("--latest と --until は同時に指定できません"))
+	}
+	if since != "" {
+		oldest = since
+	}
+	if until != "" {
+		latest = until
+	}
+
+	// 期間のショートカット（--on / --week / --month）
+	var shortcuts []string
+	var start, end time.Time
+	var err error
+	now := time.Now()
+	if on, _ := cmd.Flags().GetString("on"); on != "" {
+		shortcuts = append(shortcuts, "--on")
+		start, end, err = slack.ParseDayRange(on, loc, now)
+	}
+	if week, _ := cmd.Flags().GetString("week"); week != "" && err == nil {
+		shortcuts = append(shortcuts, "--week")
+		start, end, err = slack.ParseWeekRange(week, loc, now)
+	}
+	if month, _ := cmd.Flags().GetString("month"); month != "" && err == nil {
+		shortcuts = append(shortcuts, "--month")
+		start, end, err = slack.ParseMonthRange(month, loc, now)
+	}
+	if err != nil {
+		return "", "", err
+	}
+	if len(shortcuts) > 0 {
+		if len(shortcuts) > 1 || oldest != "" || latest != "" {
+			return "", "", errors.New(i18n.T("--on / --week / --month は互いに、また --oldest / --latest / --since / --until と同時に指定できません"))
+		}
+		return strconv.FormatInt(start.Unix(), 10), strconv.FormatInt(end.Unix(), 10), nil
+	}
+
+	// 相対指定・メッセージURLなどを設定されたタイムゾーンで解決
+	if oldest != "" {
+		if oldest, err = slack.ParseTimeBoundary(oldest, loc, now); err != nil {
+			return "", "", fmt.Errorf(i18n.T("oldest の日時解析に失敗しました: %v"), err)
+		}
+	}
+	if latest != "" {
+		if latest, err = slack.ParseTimeBoundary(latest, loc, now); err != nil {
+			return "", "", fmt.Errorf(i18n.T("latest の日時解析に失敗しました: %v"), err)
+		}
+	}
+	return oldest, latest, nil
+}
+
+// profileForURLs returns the profile whose workspace the URLs belong to ("" if none matches).
+// URLs of workspaces with different profiles are rejected because only one token is used per run.
+func profileForURLs(cfg *config.Config, urls []string) (string, error) {
+	var names, matched []string
+	for _, rawURL := range urls {
+		parsed, err := slack.ParseURL(rawURL)
+		if err != nil {
 			continue
 		}
-		if strings.Contains(line, "--- ここまで ---") {
-			// フッターは削除（重要ではないため）
+		profile, ok := cfg.ProfileForURL(parsed.Host, parsed.TeamID)
+		if !ok || containsString(names, profile) {
 			continue
 		}
+		names = append(names, profile)
+		matched = append(matched, fmt.Sprintf("%s (%s)", profile, rawURL))
+	}
+
+	if len(names) > 1 {
+		return "", fmt.Errorf(i18n.T("異なるプロファイルのワークスペースのURLが指定されています: %s（1回の実行では1つのワークスペースのURLのみ指定できます）"), strings.Join(matched, ", "))
+	}
+	if len(names) == 1 {
+		return names[0], nil
+	}
+	return "", nil
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// loadConfig loads the configuration and applies a profile chosen by --profile, the host or team ID of
+// the given URLs, or current_profile (in this order). Without profiles the top-level settings are used,
+// and URLs that match different profiles are an error.
+// The token is then resolved from --token, --token-fd, the environment, token_command or the config file;
+// a profile chosen by --profile or a URL takes precedence over the environment.
+func loadConfig(cmd *cobra.Command, urls ...string) (*config.Config, error) {
+	cfg, err := config.NewConfigManager().Load()
+	if err != nil {
+		return nil, err
+	}
 
-		// その他の行はそのまま追加（メッセージ形式は変更しない）
-		result = append(result, line)
+	name, _ := cmd.Flags().GetString("profile")
+	if name == "" {
+		// URLのホスト・ワークスペースIDに一致するプロファイルを選択
+		if name, err = profileForURLs(cfg, urls); err != nil {
+			return nil, err
+		}
+	}
+	explicit := name != ""
+	if name == "" {
+		name = cfg.CurrentProfile
+	}
+	if name != "" {
+		if cfg, err = cfg.WithProfile(name); err != nil {
+			return nil, err
+		}
 	}
 
-	return strings.Join(result, "\n")
+	token, _ := cmd.Flags().GetString("token")
+	fd, _ := cmd.Flags().GetInt("token-fd")
+	if err := cfg.ResolveToken(config.TokenOverrides{Token: token, FD: fd, ExplicitProfile: explicit}); err != nil {
+		return nil, err
+	}
+	return cfg, nil
 }
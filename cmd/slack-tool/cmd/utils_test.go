@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shellme/slack-tool/internal/config"
+)
+
+func TestProfileForURLs(t *testing.T) {
+	cfg := &config.Config{Profiles: map[string]*config.Profile{
+		"acme":    {TeamDomain: "acme", TeamID: "T11111111"},
+		"partner": {TeamDomain: "partner"},
+	}}
+
+	tests := []struct {
+		name    string
+		urls    []string
+		want    string
+		wantErr bool
+	}{
+		{"no urls", nil, "", false},
+		{"unknown workspace", []string{"https://other.slack.com/archives/C12345678"}, "", false},
+		{"host", []string{"https://acme.slack.com/archives/C12345678"}, "acme", false},
+		{"team id", []string{"https://app.slack.com/client/T11111111/C12345678"}, "acme", false},
+		{
+			"same profile",
+			[]string{"https://acme.slack.com/archives/C12345678", "https://acme.enterprise.slack.com/archives/C87654321", "not a url"},
+			"acme", false,
+		},
+		{
+			"unknown workspace with a profile",
+			[]string{"https://other.slack.com/archives/C12345678", "https://partner.slack.com/archives/C87654321"},
+			"partner", false,
+		},
+		{
+			"different profiles",
+			[]string{"https://acme.slack.com/archives/C12345678", "https://partner.slack.com/archives/C87654321"},
+			"", true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := profileForURLs(cfg, tt.urls)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "acme") || !strings.Contains(err.Error(), "partner") {
+					t.Errorf("profileForURLs() error = %v, want an error naming both profiles", err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("profileForURLs() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
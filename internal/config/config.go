@@ -5,11 +5,24 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+
+	"github.com/shellme/slack-tool/internal/i18n"
 )
 
 // Config represents the application configuration
 type Config struct {
-	SlackToken string `json:"slack_token"`
+	SlackToken   string `json:"slack_token"`
+	TokenCommand string `json:"token_command,omitempty"` // トークンを標準出力に書き出すコマンド（例: pass show slack）
+	Timezone     string `json:"timezone,omitempty"`      // 表示・日時指定に使用するタイムゾーン（例: Asia/Tokyo）
+	Language     string `json:"language,omitempty"`      // 表示言語（ja / en）。省略時は LANG / LC_ALL から判定
+
+	AnonymizePatterns []string `json:"anonymize_patterns,omitempty"` // --anonymize で追加でマスクする正規表現（例: 社員番号）
+
+	Profiles       map[string]*Profile `json:"profiles,omitempty"`        // ワークスペースごとの設定
+	CurrentProfile string              `json:"current_profile,omitempty"` // URLから判定できない場合に使用するプロファイル
+
+	ActiveProfile string      `json:"-"` // WithProfile で適用したプロファイル
+	TokenSource   TokenSource `json:"-"` // ResolveToken で SlackToken を取得した場所
 }
 
 // ConfigManager handles configuration file operations
@@ -21,7 +34,7 @@ type ConfigManager struct {
 func NewConfigManager() *ConfigManager {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		panic(fmt.Sprintf("ホームディレクトリを取得できませんでした: %v", err))
+		panic(fmt.Sprintf(i18n.T("ホームディレクトリを取得できませんでした: %v"), err))
 	}
 
 	configDir := filepath.Join(homeDir, ".config", "slack-tool")
@@ -41,12 +54,12 @@ func (cm *ConfigManager) Load() (*Config, error) {
 
 	data, err := os.ReadFile(cm.configPath)
 	if err != nil {
-		return nil, fmt.Errorf("設定ファイルの読み込みに失敗しました: %v", err)
+		return nil, fmt.Errorf(i18n.T("設定ファイルの読み込みに失敗しました: %v"), err)
 	}
 
 	var config Config
 	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("設定ファイルの解析に失敗しました: %v", err)
+		return nil, fmt.Errorf(i18n.T("設定ファイルの解析に失敗しました: %v"), err)
 	}
 
 	return &config, nil
@@ -57,16 +70,16 @@ func (cm *ConfigManager) Save(config *Config) error {
 	// 設定ディレクトリを作成
 	configDir := filepath.Dir(cm.configPath)
 	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return fmt.Errorf("設定ディレクトリの作成に失敗しました: %v", err)
+		return fmt.Errorf(i18n.T("設定ディレクトリの作成に失敗しました: %v"), err)
 	}
 
 	data, err := json.MarshalIndent(config, "", "  ")
 	if err != nil {
-		return fmt.Errorf("設定のJSON変換に失敗しました: %v", err)
+		return fmt.Errorf(i18n.T("設定のJSON変換に失敗しました: %v"), err)
 	}
 
 	if err := os.WriteFile(cm.configPath, data, 0600); err != nil {
-		return fmt.Errorf("設定ファイルの保存に失敗しました: %v", err)
+		return fmt.Errorf(i18n.T("設定ファイルの保存に失敗しました: %v"), err)
 	}
 
 	return nil
@@ -76,3 +89,8 @@ func (cm *ConfigManager) Save(config *Config) error {
 func (cm *ConfigManager) GetConfigPath() string {
 	return cm.configPath
 }
+
+// GetTemplatesDir returns the directory for user-defined output templates
+func (cm *ConfigManager) GetTemplatesDir() string {
+	return filepath.Join(filepath.Dir(cm.configPath), "templates")
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shellme/slack-tool/internal/i18n"
+)
+
+// Profile holds the token and defaults for one workspace
+type Profile struct {
+	SlackToken   string `json:"slack_token,omitempty"`
+	TokenCommand string `json:"token_command,omitempty"` // 指定時は共通の token_command の代わりに使用
+	TeamDomain   string `json:"team_domain,omitempty"`   // ワークスペースのドメイン（例: acme は acme.slack.com・acme.enterprise.slack.com に一致）
+	TeamID       string `json:"team_id,omitempty"`       // ワークスペースID（app.slack.com・slack:// のURLで使用）
+	Timezone     string `json:"timezone,omitempty"`      // 省略時は共通の timezone
+
+	AnonymizePatterns []string `json:"anonymize_patterns,omitempty"` // 共通の anonymize_patterns に追加
+}
+
+// ProfileNames returns the profile names in alphabetical order
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ProfileForURL returns the name of the profile whose team domain matches host or whose team ID matches teamID
+func (c *Config) ProfileForURL(host, teamID string) (string, bool) {
+	host = strings.ToLower(host)
+	for _, name := range c.ProfileNames() {
+		profile := c.Profiles[name]
+		if teamID != "" && strings.EqualFold(profile.TeamID, teamID) {
+			return name, true
+		}
+		if host != "" && profile.TeamDomain != "" && matchesTeamDomain(host, strings.ToLower(profile.TeamDomain)) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// matchesTeamDomain reports whether a URL host belongs to the workspace domain,
+// e.g. acme matches acme.slack.com and acme.enterprise.slack.com (a full host must match exactly)
+func matchesTeamDomain(host, domain string) bool {
+	if strings.Contains(domain, ".") {
+		return host == domain
+	}
+	return host == domain+".slack.com" || host == domain+".enterprise.slack.com"
+}
+
+// WithProfile returns a copy of the configuration with the token and defaults of the named profile applied
+func (c *Config) WithProfile(name string) (*Config, error) {
+	profile, exists := c.Profiles[name]
+	if !exists {
+		return nil, fmt.Errorf(i18n.T("プロファイルが見つかりません: %s"), name)
+	}
+
+	resolved := *c
+	resolved.SlackToken = profile.SlackToken
+	resolved.TokenCommand = profile.TokenCommand // 別のワークスペースのトークンを取得しないよう共通の設定は使わない
+	resolved.ActiveProfile = name
+	if profile.Timezone != "" {
+		resolved.Timezone = profile.Timezone
+	}
+	resolved.AnonymizePatterns = append(append([]string(nil), c.AnonymizePatterns...), profile.AnonymizePatterns...)
+	return &resolved, nil
+}
@@ -1,8 +1,15 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
 	"strings"
+
+	"github.com/shellme/slack-tool/internal/i18n"
 )
 
 // MaskToken masks a token for display purposes
@@ -22,16 +29,172 @@ func MaskToken(token string) string {
 // ValidateToken validates if the token format is correct
 func ValidateToken(token string) error {
 	if token == "" {
-		return fmt.Errorf("トークンが空です")
+		return errors.New(i18n.T("トークンが空です"))
 	}
 
 	if !strings.HasPrefix(token, "xoxp-") {
-		return fmt.Errorf("User Tokenは 'xoxp-' で始まる必要があります")
+		return errors.New(i18n.T("User Tokenは 'xoxp-' で始まる必要があります"))
 	}
 
 	if len(token) < 20 {
-		return fmt.Errorf("トークンの長さが不正です")
+		return errors.New(i18n.T("トークンの長さが不正です"))
 	}
 
 	return nil
 }
+
+// TokenEnvVars are the environment variables checked for a token, in order of precedence
+var TokenEnvVars = []string{"SLACK_TOOL_TOKEN", "SLACK_TOKEN"}
+
+// TokenSourceKind identifies where a token was read from
+type TokenSourceKind string
+
+const (
+	TokenFromFlag    TokenSourceKind = "flag"    // --token
+	TokenFromFD      TokenSourceKind = "fd"      // --token-fd
+	TokenFromEnv     TokenSourceKind = "env"     // SLACK_TOOL_TOKEN / SLACK_TOKEN
+	TokenFromCommand TokenSourceKind = "command" // token_command
+	TokenFromConfig  TokenSourceKind = "config"  // slack_token
+)
+
+// TokenSource describes where the active token came from
+type TokenSource struct {
+	Kind    TokenSourceKind
+	Detail  string // 環境変数名・ファイルディスクリプタの番号・実行したコマンド
+	Profile string // プロファイルの設定から取得した場合のプロファイル名
+}
+
+// String returns a description of the source for display
+func (s TokenSource) String() string {
+	var description string
+	switch s.Kind {
+	case TokenFromFlag:
+		return i18n.T("--token フラグ")
+	case TokenFromFD:
+		return fmt.Sprintf(i18n.T("ファイルディスクリプタ %s（--token-fd）"), s.Detail)
+	case TokenFromEnv:
+		return fmt.Sprintf(i18n.T("環境変数 %s"), s.Detail)
+	case TokenFromCommand:
+		description = fmt.Sprintf(i18n.T("token_command（%s）"), s.Detail)
+	case TokenFromConfig:
+		description = "slack_token"
+	default:
+		return ""
+	}
+	if s.Profile != "" {
+		return fmt.Sprintf(i18n.T("プロファイル %s の %s"), s.Profile, description)
+	}
+	return fmt.Sprintf(i18n.T("設定ファイルの %s"), description)
+}
+
+// TokenOverrides are the token sources given on the command line
+type TokenOverrides struct {
+	Token string // --token の値
+	FD    int    // --token-fd の値（負の場合は未指定）
+
+	// ExplicitProfile is set when the profile was chosen by --profile or a URL rather than current_profile;
+	// its token then takes precedence over the environment variables
+	ExplicitProfile bool
+}
+
+// ResolveToken sets SlackToken from the first available source and records it in TokenSource.
+// Precedence: --token, --token-fd, SLACK_TOOL_TOKEN, SLACK_TOKEN, token_command, slack_token
+// (token_command and slack_token are those of the active profile, if any). The token of a profile
+// chosen by --profile or a URL precedes the environment variables.
+func (c *Config) ResolveToken(overrides TokenOverrides) error {
+	if overrides.Token != "" {
+		c.SlackToken, c.TokenSource = overrides.Token, TokenSource{Kind: TokenFromFlag}
+		return nil
+	}
+
+	if overrides.FD >= 0 {
+		token, err := readTokenFD(overrides.FD)
+		if err != nil {
+			return err
+		}
+		c.SlackToken, c.TokenSource = token, TokenSource{Kind: TokenFromFD, Detail: fmt.Sprint(overrides.FD)}
+		return nil
+	}
+
+	// 明示したプロファイルのトークンは別のワークスペースのものかもしれない環境変数より優先
+	if overrides.ExplicitProfile && c.ActiveProfile != "" {
+		if found, err := c.resolveConfigToken(); found || err != nil {
+			return err
+		}
+	}
+
+	for _, name := range TokenEnvVars {
+		if token := strings.TrimSpace(os.Getenv(name)); token != "" {
+			c.SlackToken, c.TokenSource = token, TokenSource{Kind: TokenFromEnv, Detail: name}
+			return nil
+		}
+	}
+
+	_, err := c.resolveConfigToken()
+	return err
+}
+
+// resolveConfigToken sets SlackToken from token_command or slack_token and reports whether either is set
+func (c *Config) resolveConfigToken() (bool, error) {
+	if c.TokenCommand != "" {
+		token, err := runTokenCommand(c.TokenCommand)
+		if err != nil {
+			return false, err
+		}
+		c.SlackToken, c.TokenSource = token, TokenSource{Kind: TokenFromCommand, Detail: c.TokenCommand, Profile: c.ActiveProfile}
+		return true, nil
+	}
+
+	if c.SlackToken != "" {
+		c.TokenSource = TokenSource{Kind: TokenFromConfig, Profile: c.ActiveProfile}
+		return true, nil
+	}
+	return false, nil
+}
+
+// readTokenFD reads a token from an open file descriptor, e.g. --token-fd 3 with 3< token.txt
+func readTokenFD(fd int) (string, error) {
+	file := os.NewFile(uintptr(fd), fmt.Sprintf("fd%d", fd))
+	if file == nil {
+		return "", fmt.Errorf(i18n.T("ファイルディスクリプタ %d を開けません"), fd)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, 4096))
+	if err != nil {
+		return "", fmt.Errorf(i18n.T("ファイルディスクリプタ %d からトークンを読み込めませんでした: %v"), fd, err)
+	}
+	token := firstLine(data)
+	if token == "" {
+		return "", fmt.Errorf(i18n.T("ファイルディスクリプタ %d から読み込んだトークンが空です"), fd)
+	}
+	return token, nil
+}
+
+// runTokenCommand runs a credential helper through the shell and returns the token it prints
+func runTokenCommand(command string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Stdin = os.Stdin   // パスフレーズの入力などに対応
+	cmd.Stderr = os.Stderr // ヘルパーのエラーメッセージはそのまま表示
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf(i18n.T("token_command の実行に失敗しました: %v"), err)
+	}
+	token := firstLine(output)
+	if token == "" {
+		return "", errors.New(i18n.T("token_command の出力が空です"))
+	}
+	return token, nil
+}
+
+// firstLine returns the first line of the output (pass show などは2行目以降に付加情報を含むことがある)
+func firstLine(data []byte) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(string(data)), "\n")
+	return strings.TrimSpace(line)
+}
@@ -0,0 +1,56 @@
+package config
+
+import "testing"
+
+func TestResolveToken(t *testing.T) {
+	base := &Config{
+		SlackToken: "xoxp-top",
+		Profiles: map[string]*Profile{
+			"acme":    {SlackToken: "xoxp-acme"},
+			"helper":  {TokenCommand: "echo xoxp-helper"},
+			"partner": {},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		profile    string
+		env        string
+		overrides  TokenOverrides
+		want       string
+		wantSource TokenSourceKind
+	}{
+		{"config file", "", "", TokenOverrides{FD: -1}, "xoxp-top", TokenFromConfig},
+		{"environment over config file", "", "xoxp-env", TokenOverrides{FD: -1}, "xoxp-env", TokenFromEnv},
+		{"flag over environment", "acme", "xoxp-env", TokenOverrides{Token: "xoxp-flag", FD: -1, ExplicitProfile: true}, "xoxp-flag", TokenFromFlag},
+		{"environment over current profile", "acme", "xoxp-env", TokenOverrides{FD: -1}, "xoxp-env", TokenFromEnv},
+		{"explicit profile over environment", "acme", "xoxp-env", TokenOverrides{FD: -1, ExplicitProfile: true}, "xoxp-acme", TokenFromConfig},
+		{"explicit profile command over environment", "helper", "xoxp-env", TokenOverrides{FD: -1, ExplicitProfile: true}, "xoxp-helper", TokenFromCommand},
+		{"explicit profile without token", "partner", "xoxp-env", TokenOverrides{FD: -1, ExplicitProfile: true}, "xoxp-env", TokenFromEnv},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SLACK_TOOL_TOKEN", "")
+			t.Setenv("SLACK_TOKEN", tt.env)
+
+			cfg := base
+			if tt.profile != "" {
+				var err error
+				if cfg, err = base.WithProfile(tt.profile); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				copied := *base
+				cfg = &copied
+			}
+
+			if err := cfg.ResolveToken(tt.overrides); err != nil {
+				t.Fatal(err)
+			}
+			if cfg.SlackToken != tt.want || cfg.TokenSource.Kind != tt.wantSource {
+				t.Errorf("ResolveToken() = %q from %s, want %q from %s", cfg.SlackToken, cfg.TokenSource.Kind, tt.want, tt.wantSource)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+// Package i18n provides the message catalogue used for CLI and formatter output.
+//
+// Messages are written in Japanese in the source code and used as catalogue keys.
+// When English is selected, T looks up the English translation of the key.
+package i18n
+
+import (
+	"os"
+	"strings"
+)
+
+// Language represents an output language
+type Language string
+
+const (
+	// Japanese is the default language
+	Japanese Language = "ja"
+	// English uses the translations in the English catalogue
+	English Language = "en"
+)
+
+// current is the language used by T
+var current = Japanese
+
+// SetLanguage sets the language used by T
+func SetLanguage(lang Language) {
+	current = lang
+}
+
+// Current returns the language used by T
+func Current() Language {
+	return current
+}
+
+// T returns the message for the current language.
+// The key is the Japanese message; it is returned as-is if no translation exists.
+func T(key string) string {
+	if current == English {
+		if translated, exists := englishMessages[key]; exists {
+			return translated
+		}
+	}
+	return key
+}
+
+// ParseLanguage parses a language name such as "ja", "en" or "en_US.UTF-8"
+func ParseLanguage(value string) (Language, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch {
+	case value == "", value == "c", value == "posix", strings.HasPrefix(value, "c."):
+		// ロケール未設定とみなす
+		return "", false
+	case strings.HasPrefix(value, "ja"):
+		return Japanese, true
+	default:
+		// 日本語以外のロケールは英語で表示
+		return English, true
+	}
+}
+
+// Detect determines the language from the configured value, LC_ALL, LC_MESSAGES or LANG (default: Japanese)
+func Detect(configured string) Language {
+	if lang, ok := ParseLanguage(configured); ok {
+		return lang
+	}
+
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if lang, ok := ParseLanguage(os.Getenv(name)); ok {
+			return lang
+		}
+	}
+
+	return Japanese
+}
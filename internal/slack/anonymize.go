@@ -0,0 +1,391 @@
+package slack
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/shellme/slack-tool/internal/i18n"
+	"github.com/slack-go/slack"
+)
+
+// pseudonymPrefix is the prefix of user pseudonyms ("User A", "User B", ...)
+const pseudonymPrefix = "User "
+
+// minPhoneDigits is the number of digits in the shortest phone number masked (Japanese landline and US numbers have 10)
+const minPhoneDigits = 10
+
+// Kinds of masked values, used in placeholders such as "[email-1]"
+const (
+	maskEmail    = "email"
+	maskPhone    = "phone"
+	maskURL      = "url"
+	maskRedacted = "redacted"
+)
+
+var (
+	anonymizeEmailRegex = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`)
+	anonymizeURLRegex   = regexp.MustCompile(`(?i)(?:https?|ftp)://[^\s<>"'()\[\]]+|www\.[^\s<>"'()\[\]]+`)
+	// 例: +81 90-1234-5678 / 03-1234-5678 / 090 1234 5678 / (555) 123-4567 / 555-123-4567 / 09012345678
+	// 区切りのある番号は国番号・市外局番の 0・括弧・3-3-4桁のいずれかを必須にする（12-34-5678 のようなIDに一致しないように）
+	anonymizePhoneRegex = regexp.MustCompile(`\+\d{1,3}[\s\-]?(?:\(\d{1,4}\)\s?|\d{1,4}[\s\-])\d{1,4}[\s\-]\d{3,4}` +
+		`|(?:\(0?\d{2,4}\)\s?|0\d{1,4}[\s\-])\d{1,4}[\s\-]\d{3,4}` +
+		`|\d{3}[\s\-]\d{3}[\s\-]\d{4}` +
+		`|\+?0\d{9,10}`)
+	// 既に置き換えた値（2回目以降の匿名化で再度置き換えないため）
+	anonymizePlaceholderRegex = regexp.MustCompile(`\[(?:email|phone|url|redacted)-\d+\]`)
+)
+
+// Anonymizer replaces users with pseudonyms and masks contact details and custom patterns in text.
+// The same user or value is always replaced with the same pseudonym or placeholder.
+type Anonymizer struct {
+	patterns []*regexp.Regexp // 設定ファイルの独自パターン
+
+	users     []*AnonymizedUser
+	userIndex map[string]*AnonymizedUser // ユーザーID（IDがない場合は "name:" + 名前）→ 仮名
+	values    []AnonymizedValue
+	valueKeys map[string]string // 元の値 → プレースホルダー
+	counts    map[string]int    // 種類ごとのプレースホルダーの数
+
+	nameRegex *regexp.Regexp    // 既知の名前（nil の場合は再作成）
+	nameIndex map[string]string // 小文字の名前 → 仮名
+}
+
+// AnonymizedUser is a pseudonym and the user it replaces
+type AnonymizedUser struct {
+	Pseudonym string   `json:"pseudonym"`
+	UserID    string   `json:"user_id,omitempty"`
+	Names     []string `json:"names,omitempty"` // ハンドル・表示名・氏名など本文中で置き換える名前
+}
+
+// AnonymizedValue is a placeholder and the value it masks
+type AnonymizedValue struct {
+	Placeholder string `json:"placeholder"`
+	Value       string `json:"value"`
+}
+
+// AnonymizeMapping is the content of a mapping file used to reverse anonymization
+type AnonymizeMapping struct {
+	Users  []AnonymizedUser  `json:"users"`
+	Values []AnonymizedValue `json:"values"`
+}
+
+// NewAnonymizer creates an anonymizer; patterns are additional regular expressions to mask
+func NewAnonymizer(patterns []string) (*Anonymizer, error) {
+	a := &Anonymizer{
+		userIndex: make(map[string]*AnonymizedUser),
+		valueKeys: make(map[string]string),
+		counts:    make(map[string]int),
+	}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf(i18n.T("匿名化パターンが不正です: %s: %v"), pattern, err)
+		}
+		a.patterns = append(a.patterns, re)
+	}
+	return a, nil
+}
+
+// LoadMapping restores pseudonyms and placeholders from a previous run so they stay the same
+func (a *Anonymizer) LoadMapping(mapping AnonymizeMapping) {
+	for _, user := range mapping.Users {
+		entry := user
+		a.users = append(a.users, &entry)
+		a.userIndex[userKey(entry.UserID, entry.Names)] = &entry
+	}
+	for _, value := range mapping.Values {
+		a.values = append(a.values, value)
+		a.valueKeys[value.Value] = value.Placeholder
+		kind, number := parsePlaceholder(value.Placeholder)
+		a.counts[kind] = max(a.counts[kind], number)
+	}
+	a.nameRegex = nil
+}
+
+// Mapping returns the pseudonyms and placeholders used so far
+func (a *Anonymizer) Mapping() AnonymizeMapping {
+	mapping := AnonymizeMapping{Users: []AnonymizedUser{}, Values: append([]AnonymizedValue{}, a.values...)}
+	for _, user := range a.users {
+		mapping.Users = append(mapping.Users, *user)
+	}
+	return mapping
+}
+
+// User returns the pseudonym of a Slack user and remembers the user's names for replacement in text
+func (a *Anonymizer) User(user *slack.User) string {
+	return a.Pseudonym(user.ID, user.Name, user.Profile.DisplayName, user.RealName, user.Profile.FirstName, user.Profile.LastName)
+}
+
+// Pseudonym returns the pseudonym for a user ID (or for the names if the ID is unknown),
+// assigning the next one ("User A", "User B", ...) on first use
+func (a *Anonymizer) Pseudonym(userID string, names ...string) string {
+	key := userKey(userID, names)
+	entry, exists := a.userIndex[key]
+	if !exists {
+		entry = &AnonymizedUser{Pseudonym: pseudonymPrefix + pseudonymLetters(len(a.users)), UserID: userID}
+		a.users = append(a.users, entry)
+		a.userIndex[key] = entry
+	}
+
+	// 本文中で置き換える名前を追加
+	for _, name := range names {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "@"))
+		if utf8.RuneCountInString(name) < 2 || containsFold(entry.Names, name) {
+			continue
+		}
+		entry.Names = append(entry.Names, name)
+		a.nameRegex = nil
+	}
+	return entry.Pseudonym
+}
+
+// AnonymizeText masks emails, phone numbers, URLs and custom patterns, then replaces known user names with pseudonyms
+func (a *Anonymizer) AnonymizeText(text string) string {
+	if text == "" {
+		return text
+	}
+	return a.replaceNames(a.maskValues(text))
+}
+
+// AnonymizeDocument anonymizes the text of all messages and attached files of a document and its sections
+func (a *Anonymizer) AnonymizeDocument(doc *Document) {
+	a.anonymizeMessages(doc.Messages)
+	for _, section := range doc.Sections {
+		a.AnonymizeDocument(section)
+	}
+}
+
+// anonymizeMessages anonymizes messages and their replies in place
+func (a *Anonymizer) anonymizeMessages(messages []DocumentMessage) {
+	for i := range messages {
+		msg := &messages[i]
+		msg.Text = a.AnonymizeText(msg.Text)
+		msg.Markdown = a.AnonymizeText(msg.Markdown)
+		for j := range msg.Files {
+			msg.Files[j].Name = a.AnonymizeText(msg.Files[j].Name)
+			msg.Files[j].Title = a.AnonymizeText(msg.Files[j].Title)
+			msg.Files[j].Permalink = a.AnonymizeText(msg.Files[j].Permalink)
+		}
+		a.anonymizeMessages(msg.Replies)
+	}
+}
+
+// maskMatch is a value found by one of the mask patterns
+type maskMatch struct {
+	start, end int
+	kind       string // 空の場合は既存のプレースホルダー（置き換えない）
+}
+
+// maskValues replaces emails, phone numbers, URLs and custom patterns with placeholders.
+// Matches are collected from all patterns first; overlapping matches keep the earliest and longest.
+func (a *Anonymizer) maskValues(text string) string {
+	var matches []maskMatch
+	collect := func(re *regexp.Regexp, kind string) {
+		for _, loc := range re.FindAllStringIndex(text, -1) {
+			start, end := loc[0], loc[1]
+			switch kind {
+			case maskURL:
+				// 文末の句読点はURLに含めない
+				end = start + len(strings.TrimRight(text[start:end], ".,;:!?"))
+			case maskPhone:
+				// 数字の途中から・途中までの一致（日付・IDなど）と10桁未満の番号は除外
+				if startsOrEndsInNumber(text, start, end) || countDigits(text[start:end]) < minPhoneDigits {
+					continue
+				}
+			}
+			if end > start {
+				matches = append(matches, maskMatch{start, end, kind})
+			}
+		}
+	}
+	collect(anonymizePlaceholderRegex, "")
+	collect(anonymizeEmailRegex, maskEmail)
+	collect(anonymizeURLRegex, maskURL)
+	collect(anonymizePhoneRegex, maskPhone)
+	for _, re := range a.patterns {
+		collect(re, maskRedacted)
+	}
+	if len(matches) == 0 {
+		return text
+	}
+
+	// 開始位置順（同じ位置なら長い方、同じ長さなら先に追加したパターン）に並べて重なりを除く
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].start != matches[j].start {
+			return matches[i].start < matches[j].start
+		}
+		return matches[i].end > matches[j].end
+	})
+
+	var result strings.Builder
+	last := 0
+	for _, match := range matches {
+		if match.start < last {
+			continue
+		}
+		result.WriteString(text[last:match.start])
+		if match.kind == "" {
+			result.WriteString(text[match.start:match.end])
+		} else {
+			result.WriteString(a.placeholder(match.kind, text[match.start:match.end]))
+		}
+		last = match.end
+	}
+	result.WriteString(text[last:])
+	return result.String()
+}
+
+// placeholder returns the placeholder for a value, assigning the next number of its kind on first use
+func (a *Anonymizer) placeholder(kind, value string) string {
+	if placeholder, exists := a.valueKeys[value]; exists {
+		return placeholder
+	}
+	a.counts[kind]++
+	placeholder := "[" + kind + "-" + strconv.Itoa(a.counts[kind]) + "]"
+	a.valueKeys[value] = placeholder
+	a.values = append(a.values, AnonymizedValue{Placeholder: placeholder, Value: value})
+	return placeholder
+}
+
+// replaceNames replaces the known names of users with their pseudonyms (case-insensitive, whole words for Latin names)
+func (a *Anonymizer) replaceNames(text string) string {
+	if a.nameRegex == nil {
+		a.buildNameRegex()
+	}
+	if a.nameRegex == nil {
+		return text
+	}
+	return a.nameRegex.ReplaceAllStringFunc(text, func(name string) string {
+		if pseudonym, exists := a.nameIndex[strings.ToLower(name)]; exists {
+			return pseudonym
+		}
+		return name
+	})
+}
+
+// buildNameRegex compiles the known names into one pattern, longest first
+func (a *Anonymizer) buildNameRegex() {
+	a.nameIndex = make(map[string]string)
+	var names []string
+	for _, user := range a.users {
+		for _, name := range user.Names {
+			key := strings.ToLower(name)
+			if _, exists := a.nameIndex[key]; exists {
+				continue
+			}
+			a.nameIndex[key] = user.Pseudonym
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+	sort.SliceStable(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+
+	alternatives := make([]string, len(names))
+	for i, name := range names {
+		// 英数字で始まる・終わる名前は単語単位で一致させる（"Al" が "Also" に一致しないように）
+		pattern := regexp.QuoteMeta(name)
+		if first, _ := utf8.DecodeRuneInString(name); isASCIIWordRune(first) {
+			pattern = `\b` + pattern
+		}
+		if last, _ := utf8.DecodeLastRuneInString(name); isASCIIWordRune(last) {
+			pattern += `\b`
+		}
+		alternatives[i] = pattern
+	}
+	a.nameRegex = regexp.MustCompile(`(?i)` + strings.Join(alternatives, "|"))
+}
+
+// Restore reverses anonymization: placeholders become the original values and pseudonyms the users' handles
+func (m AnonymizeMapping) Restore(text string) string {
+	var pairs []string
+	for _, value := range m.Values {
+		pairs = append(pairs, value.Placeholder, value.Value)
+	}
+
+	// "User A" が "User AB" の一部に一致しないよう長い仮名から置き換える
+	users := append([]AnonymizedUser{}, m.Users...)
+	sort.SliceStable(users, func(i, j int) bool { return len(users[i].Pseudonym) > len(users[j].Pseudonym) })
+	for _, user := range users {
+		name := user.UserID
+		if len(user.Names) > 0 {
+			name = user.Names[0]
+		}
+		if name != "" {
+			pairs = append(pairs, user.Pseudonym, name)
+		}
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// userKey returns the key identifying a user: the user ID, or the first name for users without an ID
+func userKey(userID string, names []string) string {
+	if userID != "" {
+		return userID
+	}
+	for _, name := range names {
+		if name != "" {
+			return "name:" + strings.ToLower(strings.TrimPrefix(name, "@"))
+		}
+	}
+	return ""
+}
+
+// pseudonymLetters returns A, B, ..., Z, AA, AB, ... for 0, 1, ...
+func pseudonymLetters(index int) string {
+	letters := ""
+	for index >= 0 {
+		letters = string(rune('A'+index%26)) + letters
+		index = index/26 - 1
+	}
+	return letters
+}
+
+// parsePlaceholder returns the kind and number of a placeholder such as "[email-3]"
+func parsePlaceholder(placeholder string) (string, int) {
+	body := strings.TrimSuffix(strings.TrimPrefix(placeholder, "["), "]")
+	kind, number, _ := strings.Cut(body, "-")
+	n, _ := strconv.Atoi(number)
+	return kind, n
+}
+
+// startsOrEndsInNumber reports whether text[start:end] is preceded or followed by a digit
+func startsOrEndsInNumber(text string, start, end int) bool {
+	if before, _ := utf8.DecodeLastRuneInString(text[:start]); unicode.IsDigit(before) {
+		return true
+	}
+	after, _ := utf8.DecodeRuneInString(text[end:])
+	return unicode.IsDigit(after)
+}
+
+// countDigits returns the number of digits in text
+func countDigits(text string) int {
+	count := 0
+	for _, r := range text {
+		if unicode.IsDigit(r) {
+			count++
+		}
+	}
+	return count
+}
+
+// isASCIIWordRune reports whether r matches \w in regular expressions
+func isASCIIWordRune(r rune) bool {
+	return r < utf8.RuneSelf && (r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// containsFold reports whether values contains value, ignoring case
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
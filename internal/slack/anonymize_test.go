@@ -0,0 +1,203 @@
+package slack
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func TestAnonymizerMasksValues(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		// メールアドレス
+		{"email", "mail taro.yamada+x@mail.example.co.jp now", "mail [email-1] now"},
+		{"same email", "taro@example.com and TARO@example.com, taro@example.com", "[email-1] and [email-2], [email-1]"},
+
+		// URL
+		{"url", "see https://example.com/a?b=1.", "see [url-1]."},
+		{"url in parentheses", "(http://example.com/x)", "([url-1])"},
+		{"www", "www.example.com/docs", "[url-1]"},
+		{"url containing a phone number", "https://example.com/tel/03-1234-5678", "[url-1]"},
+
+		// 電話番号
+		{"japanese landline", "03-1234-5678", "[phone-1]"},
+		{"japanese mobile", "call 090 1234 5678 now", "call [phone-1] now"},
+		{"international", "+81 90-1234-5678", "[phone-1]"},
+		{"parenthesized area code", "(555) 123-4567", "[phone-1]"},
+		{"us number", "555-123-4567", "[phone-1]"},
+		{"without separators", "09012345678", "[phone-1]"},
+
+		// 電話番号ではない数字
+		{"id-like groups", "ticket 12-34-5678", "ticket 12-34-5678"},
+		{"date", "2024-01-15 から 2024-12-31", "2024-01-15 から 2024-12-31"},
+		{"short number", "order 0120-45-678", "order 0120-45-678"},
+		{"slack timestamp", "ts 1700000000.123456", "ts 1700000000.123456"},
+		{"part of a longer number", "1234-5678-9012-3456", "1234-5678-9012-3456"},
+
+		// 置き換え済みの値
+		{"placeholder is kept", "[email-7] [url-3]", "[email-7] [url-3]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := NewAnonymizer(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := a.AnonymizeText(tt.text); got != tt.want {
+				t.Errorf("AnonymizeText(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnonymizerCustomPatterns(t *testing.T) {
+	a, err := NewAnonymizer([]string{`EMP-\d{6}`, `(?i)project-[a-z]+`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := "EMP-123456 joined Project-Falcon; EMP-123456 mailed x@example.com"
+	want := "[redacted-1] joined [redacted-2]; [redacted-1] mailed [email-1]"
+	if got := a.AnonymizeText(text); got != want {
+		t.Errorf("AnonymizeText(%q) = %q, want %q", text, got, want)
+	}
+
+	if _, err := NewAnonymizer([]string{"("}); err == nil {
+		t.Error("NewAnonymizer with an invalid pattern succeeded")
+	}
+}
+
+func TestAnonymizerNames(t *testing.T) {
+	a, err := NewAnonymizer(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	taro := &slack.User{ID: "U1", Name: "taro", RealName: "Taro Yamada"}
+	if got := a.User(taro); got != "User A" {
+		t.Errorf("User(taro) = %q, want %q", got, "User A")
+	}
+	if got := a.Pseudonym("U2", "al"); got != "User B" {
+		t.Errorf("Pseudonym(U2) = %q, want %q", got, "User B")
+	}
+	if got := a.User(taro); got != "User A" {
+		t.Errorf("User(taro) again = %q, want %q", got, "User A")
+	}
+
+	// 長い名前を優先し、英数字の名前は単語単位で置き換える
+	text := "Taro Yamada, TARO and al. Also taros and 太郎"
+	want := "User A, User A and User B. Also taros and 太郎"
+	if got := a.AnonymizeText(text); got != want {
+		t.Errorf("AnonymizeText(%q) = %q, want %q", text, got, want)
+	}
+}
+
+func TestPseudonymLetters(t *testing.T) {
+	for index, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if got := pseudonymLetters(index); got != want {
+			t.Errorf("pseudonymLetters(%d) = %q, want %q", index, got, want)
+		}
+	}
+}
+
+func TestAnonymizeDocument(t *testing.T) {
+	f := newTestFormatter(
+		slack.User{ID: "U1", Name: "taro", RealName: "Taro Yamada"},
+		slack.User{ID: "U2", Name: "hanako"},
+		slack.User{ID: "U3", Name: "jiro"},
+	)
+	a, err := NewAnonymizer(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.SetAnonymizer(a)
+
+	parent := testMessage("100.000001", "100.000001", "U1", "hi <@U2>, mail taro@example.com")
+	parent.Reactions = []slack.ItemReaction{{Name: "+1", Count: 2, Users: []string{"U3", "U1"}}}
+	parent.Files = []slack.File{{Name: "notes.txt", Title: "Taro Yamada notes", Permalink: "https://files.example.com/notes"}}
+	reply := testMessage("100.000002", "100.000001", "U2", "thanks taro, see taro@example.com")
+	reply.Attachments = []slack.Attachment{{AuthorID: "U3", Ts: "90.000001", Text: "shared by jiro"}}
+
+	doc, err := f.BuildThread([]slack.Message{parent, reply}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 同じユーザー・値はメッセージ・返信・リアクション・添付のどこでも同じ仮名・プレースホルダー
+	got := doc.Messages[0]
+	if got.Author != "@User A" || got.Text != "hi @User B, mail [email-1]" {
+		t.Errorf("parent = %q %q", got.Author, got.Text)
+	}
+	if users := got.Reactions[0].Users; !reflect.DeepEqual(users, []string{"User C", "User A"}) {
+		t.Errorf("reaction users = %v, want [User C User A]", users)
+	}
+	if file := got.Files[0]; file.Title != "User A notes" || file.Permalink != "[url-1]" {
+		t.Errorf("file = %+v", file)
+	}
+	wantReply := "thanks User A, see [email-1]\n\n> [共有メッセージ] @User C (1970-01-01 09:01)\n> shared by User C"
+	if r := got.Replies[0]; r.Author != "@User B" || r.Text != wantReply {
+		t.Errorf("reply = %q %q, want %q %q", r.Author, r.Text, "@User B", wantReply)
+	}
+
+	// JSONにユーザーID・マークアップのままの本文・実名を出力しない
+	r, err := NewRenderer("json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := RenderString(r, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, leak := range []string{`"user_id"`, `"raw_text"`, "U1", "U2", "U3", "taro", "Taro", "hanako", "jiro", "example.com"} {
+		if strings.Contains(output, leak) {
+			t.Errorf("JSON output contains %q:\n%s", leak, output)
+		}
+	}
+}
+
+func TestAnonymizeMappingRestore(t *testing.T) {
+	a, err := NewAnonymizer(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.User(&slack.User{ID: "U1", Name: "taro", RealName: "Taro Yamada"})
+	a.User(&slack.User{ID: "U2", Name: "hanako"})
+
+	original := "taro asked hanako to mail taro@example.com or call 03-1234-5678"
+	anonymized := a.AnonymizeText(original)
+	if want := "User A asked User B to mail [email-1] or call [phone-1]"; anonymized != want {
+		t.Fatalf("AnonymizeText() = %q, want %q", anonymized, want)
+	}
+
+	// 対応表をファイルに保存して読み込んだ場合と同じ
+	data, err := json.Marshal(a.Mapping())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mapping AnonymizeMapping
+	if err := json.Unmarshal(data, &mapping); err != nil {
+		t.Fatal(err)
+	}
+	if got := mapping.Restore(anonymized); got != original {
+		t.Errorf("Restore() = %q, want %q", got, original)
+	}
+
+	// 読み込んだ対応表の仮名・番号を引き継ぐ
+	next, err := NewAnonymizer(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next.LoadMapping(mapping)
+	text := "taro: taro@example.com, jiro@example.com"
+	if got, want := next.AnonymizeText(text), "User A: [email-1], [email-2]"; got != want {
+		t.Errorf("AnonymizeText() after LoadMapping = %q, want %q", got, want)
+	}
+	if got := next.Pseudonym("U3", "jiro"); got != "User C" {
+		t.Errorf("Pseudonym(U3) after LoadMapping = %q, want %q", got, "User C")
+	}
+}
@@ -0,0 +1,330 @@
+package slack
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shellme/slack-tool/internal/i18n"
+	"github.com/slack-go/slack"
+)
+
+// markupEscaper escapes plain text so that it survives MarkupDecoder.Decode unchanged
+var markupEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// messageBody builds the message body in Slack markup from text, blocks and attachments.
+// The result is decoded by cleanMessageText like an ordinary msg.Text.
+func (f *Formatter) messageBody(msg slack.Message, loc *time.Location) string {
+	var parts []string
+
+	// ボットやワークフローの投稿はブロックに本文があり、msg.Text は要約のみのことが多い
+	// rich_text のみの場合は msg.Text と同じ内容なので、msg.Text が空のときだけブロックを使用
+	if hasLayoutBlocks(msg.Blocks) || (strings.TrimSpace(msg.Text) == "" && len(msg.Blocks.BlockSet) > 0) {
+		if rendered := f.renderBlocks(msg.Blocks); rendered != "" {
+			parts = append(parts, rendered)
+		}
+	}
+	if len(parts) == 0 && strings.TrimSpace(msg.Text) != "" {
+		parts = append(parts, msg.Text)
+	}
+
+	// 添付（レガシー attachments・共有メッセージ・リンクの展開）
+	for _, attachment := range msg.Attachments {
+		if rendered := f.renderAttachment(attachment, loc); rendered != "" {
+			parts = append(parts, rendered)
+		}
+	}
+
+	return strings.Join(parts, "\n\n")
+}
+
+// hasLayoutBlocks reports whether blocks contain layout blocks other than rich_text
+func hasLayoutBlocks(blocks slack.Blocks) bool {
+	for _, block := range blocks.BlockSet {
+		switch block.(type) {
+		case *slack.SectionBlock, *slack.ContextBlock, *slack.HeaderBlock, *slack.ImageBlock:
+			return true
+		}
+	}
+	return false
+}
+
+// renderBlocks renders Block Kit blocks as text
+func (f *Formatter) renderBlocks(blocks slack.Blocks) string {
+	var parts []string
+	for _, block := range blocks.BlockSet {
+		if rendered := f.renderBlock(block); rendered != "" {
+			parts = append(parts, rendered)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// renderBlock renders a single block (unsupported blocks such as actions and inputs are skipped)
+func (f *Formatter) renderBlock(block slack.Block) string {
+	switch b := block.(type) {
+	case *slack.RichTextBlock:
+		return f.renderRichTextElements(b.Elements)
+
+	case *slack.SectionBlock:
+		var lines []string
+		if text := renderTextObject(b.Text); text != "" {
+			lines = append(lines, text)
+		}
+		for _, field := range b.Fields {
+			if text := renderTextObject(field); text != "" {
+				lines = append(lines, text)
+			}
+		}
+		return strings.Join(lines, "\n")
+
+	case *slack.HeaderBlock:
+		if text := renderTextObject(b.Text); text != "" {
+			return "*" + text + "*"
+		}
+
+	case *slack.ContextBlock:
+		var items []string
+		for _, element := range b.ContextElements.Elements {
+			switch e := element.(type) {
+			case *slack.TextBlockObject:
+				if text := renderTextObject(e); text != "" {
+					items = append(items, text)
+				}
+			case *slack.ImageBlockElement:
+				if e.AltText != "" {
+					items = append(items, markupEscaper.Replace(e.AltText))
+				}
+			}
+		}
+		return strings.Join(items, " ")
+
+	case *slack.ImageBlock:
+		alt := b.AltText
+		if b.Title != nil && b.Title.Text != "" {
+			alt = b.Title.Text
+		}
+		return fmt.Sprintf(i18n.T("(画像: %s)"), markupEscaper.Replace(alt))
+
+	case *slack.DividerBlock:
+		return "---"
+	}
+
+	return ""
+}
+
+// renderTextObject renders a text object; plain_text is escaped so that it is not decoded as markup
+func renderTextObject(text *slack.TextBlockObject) string {
+	if text == nil {
+		return ""
+	}
+	if text.Type == slack.PlainTextType {
+		return markupEscaper.Replace(text.Text)
+	}
+	return text.Text
+}
+
+// renderRichTextElements renders rich_text elements (sections, lists, quotes and preformatted blocks)
+func (f *Formatter) renderRichTextElements(elements []slack.RichTextElement) string {
+	var result strings.Builder
+
+	for i, element := range elements {
+		switch e := element.(type) {
+		case *slack.RichTextSection:
+			result.WriteString(renderRichTextSection(e.Elements))
+
+		case *slack.RichTextList:
+			result.WriteString(f.renderRichTextList(e))
+
+		case *slack.RichTextQuote:
+			lines := strings.Split(renderRichTextSection(e.Elements), "\n")
+			for j, line := range lines {
+				lines[j] = "&gt; " + line
+			}
+			result.WriteString(strings.Join(lines, "\n"))
+
+		case *slack.RichTextPreformatted:
+			result.WriteString("```\n")
+			result.WriteString(strings.TrimSuffix(renderRichTextSection(e.Elements), "\n"))
+			result.WriteString("\n```")
+		}
+
+		// 要素間の改行（セクションは末尾に改行を含むことが多いため重複させない）
+		if i < len(elements)-1 && !strings.HasSuffix(result.String(), "\n") {
+			result.WriteString("\n")
+		}
+	}
+
+	return strings.TrimRight(result.String(), "\n")
+}
+
+// renderRichTextList renders a bullet or ordered list with its indentation
+func (f *Formatter) renderRichTextList(list *slack.RichTextList) string {
+	indent := strings.Repeat("  ", list.Indent)
+
+	var lines []string
+	for i, item := range list.Elements {
+		marker := "- "
+		if list.Style == slack.RTEListOrdered {
+			marker = strconv.Itoa(list.Offset+i+1) + ". "
+		}
+
+		var text string
+		switch e := item.(type) {
+		case *slack.RichTextSection:
+			text = renderRichTextSection(e.Elements)
+		default:
+			text = f.renderRichTextElements([]slack.RichTextElement{item})
+		}
+		lines = append(lines, indent+marker+strings.TrimRight(text, "\n"))
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// renderRichTextSection renders inline rich_text elements as Slack markup
+func renderRichTextSection(elements []slack.RichTextSectionElement) string {
+	var result strings.Builder
+
+	for _, element := range elements {
+		switch e := element.(type) {
+		case *slack.RichTextSectionTextElement:
+			result.WriteString(applyRichTextStyle(markupEscaper.Replace(e.Text), e.Style))
+		case *slack.RichTextSectionUserElement:
+			result.WriteString("<@" + e.UserID + ">")
+		case *slack.RichTextSectionChannelElement:
+			result.WriteString("<#" + e.ChannelID + ">")
+		case *slack.RichTextSectionUserGroupElement:
+			result.WriteString("<!subteam^" + e.UsergroupID + ">")
+		case *slack.RichTextSectionBroadcastElement:
+			result.WriteString("<!" + e.Range + ">")
+		case *slack.RichTextSectionEmojiElement:
+			result.WriteString(":" + e.Name + ":")
+		case *slack.RichTextSectionLinkElement:
+			if e.Text != "" {
+				result.WriteString("<" + e.URL + "|" + markupEscaper.Replace(e.Text) + ">")
+			} else {
+				result.WriteString("<" + e.URL + ">")
+			}
+		case *slack.RichTextSectionDateElement:
+			if e.Fallback != nil && *e.Fallback != "" {
+				result.WriteString(markupEscaper.Replace(*e.Fallback))
+			} else {
+				result.WriteString("<!date^" + strconv.FormatInt(int64(e.Timestamp), 10) + "^{date_num} {time}>")
+			}
+		case *slack.RichTextSectionColorElement:
+			result.WriteString(e.Value)
+		}
+	}
+
+	return result.String()
+}
+
+// applyRichTextStyle wraps text with mrkdwn style markers
+func applyRichTextStyle(text string, style *slack.RichTextSectionTextStyle) string {
+	if style == nil || strings.TrimSpace(text) == "" {
+		return text
+	}
+
+	// 前後の空白は記号の外側に出す（"* bold*" のような無効な記法を避ける）
+	trimmed := strings.TrimSpace(text)
+	start := strings.Index(text, trimmed)
+	leading, trailing := text[:start], text[start+len(trimmed):]
+
+	if style.Code {
+		return leading + "`" + trimmed + "`" + trailing
+	}
+	if style.Bold {
+		trimmed = "*" + trimmed + "*"
+	}
+	if style.Italic {
+		trimmed = "_" + trimmed + "_"
+	}
+	if style.Strike {
+		trimmed = "~" + trimmed + "~"
+	}
+	return leading + trimmed + trailing
+}
+
+// renderAttachment renders a legacy attachment, a link unfurl or a shared message
+func (f *Formatter) renderAttachment(attachment slack.Attachment, loc *time.Location) string {
+	var lines []string
+
+	if attachment.Pretext != "" {
+		lines = append(lines, attachment.Pretext)
+	}
+
+	var body []string
+	if attachment.Title != "" {
+		if attachment.TitleLink != "" {
+			body = append(body, "*<"+attachment.TitleLink+"|"+attachment.Title+">*")
+		} else {
+			body = append(body, "*"+attachment.Title+"*")
+		}
+	}
+	if len(attachment.Blocks.BlockSet) > 0 {
+		if rendered := f.renderBlocks(attachment.Blocks); rendered != "" {
+			body = append(body, rendered)
+		}
+	} else if attachment.Text != "" {
+		body = append(body, attachment.Text)
+	}
+	for _, field := range attachment.Fields {
+		if field.Title != "" {
+			body = append(body, "*"+field.Title+"*: "+field.Value)
+		} else if field.Value != "" {
+			body = append(body, field.Value)
+		}
+	}
+	if attachment.Footer != "" {
+		body = append(body, attachment.Footer)
+	}
+	if len(body) == 0 && attachment.Fallback != "" && attachment.Pretext == "" {
+		body = append(body, attachment.Fallback)
+	}
+
+	// 共有・転送されたメッセージは元の投稿者付きの引用として表示
+	if isSharedMessage(attachment) {
+		header := i18n.T("[共有メッセージ] ") + f.attachmentAuthor(attachment)
+		if timestamp, err := f.parseTimestamp(string(attachment.Ts)); err == nil {
+			header += " (" + timestamp.In(loc).Format("2006-01-02 15:04") + ")"
+		}
+		quoted := []string{header}
+		quoted = append(quoted, body...)
+		if attachment.FromURL != "" {
+			quoted = append(quoted, i18n.T("元の投稿: ")+attachment.FromURL)
+		}
+		for _, line := range strings.Split(strings.Join(quoted, "\n"), "\n") {
+			lines = append(lines, "&gt; "+line)
+		}
+	} else {
+		if attachment.AuthorName != "" {
+			lines = append(lines, attachment.AuthorName)
+		}
+		lines = append(lines, body...)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// isSharedMessage reports whether an attachment is a shared (forwarded) message
+func isSharedMessage(attachment slack.Attachment) bool {
+	return attachment.Ts != "" && (attachment.AuthorID != "" || attachment.AuthorName != "")
+}
+
+// attachmentAuthor returns the original author of a shared message
+func (f *Formatter) attachmentAuthor(attachment slack.Attachment) string {
+	if attachment.AuthorID != "" {
+		if user, err := f.getUserInfo(attachment.AuthorID); err == nil {
+			return f.getUsername(user)
+		}
+	}
+	if f.anonymizer != nil {
+		return "@" + f.anonymizer.Pseudonym(attachment.AuthorID, attachment.AuthorSubname, attachment.AuthorName)
+	}
+	if attachment.AuthorSubname != "" {
+		return "@" + attachment.AuthorSubname
+	}
+	return "@" + attachment.AuthorName
+}
@@ -0,0 +1,109 @@
+package slack
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func TestMessageBody(t *testing.T) {
+	f := newTestFormatter(slack.User{ID: "U1", Name: "taro"})
+
+	tests := []struct {
+		name    string
+		message string // conversations.history の JSON
+		want    string
+	}{
+		{
+			"rich_text with text uses text",
+			`{"text":"hello *world*","blocks":[{"type":"rich_text","elements":[{"type":"rich_text_section","elements":[{"type":"text","text":"ignored"}]}]}]}`,
+			"hello *world*",
+		},
+		{
+			"rich_text section elements",
+			`{"text":"","blocks":[{"type":"rich_text","elements":[{"type":"rich_text_section","elements":[
+				{"type":"text","text":"Hi "},{"type":"user","user_id":"U1"},
+				{"type":"text","text":" bold","style":{"bold":true}},
+				{"type":"text","text":" a<b & c "},{"type":"emoji","name":"tada"},{"type":"text","text":" "},
+				{"type":"link","url":"https://x.com","text":"docs"},{"type":"text","text":" "},
+				{"type":"link","url":"https://y.com"},{"type":"text","text":" "},
+				{"type":"channel","channel_id":"C1"},{"type":"text","text":" "},
+				{"type":"broadcast","range":"here"},{"type":"text","text":" "},
+				{"type":"usergroup","usergroup_id":"S1"},
+				{"type":"text","text":"code","style":{"code":true}},
+				{"type":"text","text":" all ","style":{"bold":true,"italic":true,"strike":true}}
+			]}]}]}`,
+			"Hi <@U1> *bold* a&lt;b &amp; c :tada: <https://x.com|docs> <https://y.com> <#C1> <!here> <!subteam^S1>`code` ~_*all*_~ ",
+		},
+		{
+			"lists, quote and preformatted",
+			`{"blocks":[{"type":"rich_text","elements":[
+				{"type":"rich_text_section","elements":[{"type":"text","text":"Steps:\n"}]},
+				{"type":"rich_text_list","style":"ordered","indent":0,"elements":[
+					{"type":"rich_text_section","elements":[{"type":"text","text":"one"}]},
+					{"type":"rich_text_section","elements":[{"type":"text","text":"two"}]}]},
+				{"type":"rich_text_list","style":"bullet","indent":1,"elements":[
+					{"type":"rich_text_section","elements":[{"type":"text","text":"nested"}]}]},
+				{"type":"rich_text_list","style":"ordered","indent":0,"offset":2,"elements":[
+					{"type":"rich_text_section","elements":[{"type":"text","text":"three"}]}]},
+				{"type":"rich_text_quote","elements":[{"type":"text","text":"quoted\nlines"}]},
+				{"type":"rich_text_preformatted","elements":[{"type":"text","text":"x := <y>\n"}]}
+			]}]}`,
+			"Steps:\n1. one\n2. two\n  - nested\n3. three\n&gt; quoted\n&gt; lines\n```\nx := &lt;y&gt;\n```",
+		},
+		{
+			"layout blocks replace the summary text",
+			`{"text":"summary","blocks":[
+				{"type":"header","text":{"type":"plain_text","text":"Deploy <prod>"}},
+				{"type":"section","text":{"type":"mrkdwn","text":"*Status*: ok <@U1>"},"fields":[
+					{"type":"mrkdwn","text":"*Env*\nprod"},{"type":"plain_text","text":"a & b"}]},
+				{"type":"divider"},
+				{"type":"context","elements":[{"type":"mrkdwn","text":"by <@U1>"},{"type":"image","image_url":"https://x.com/i.png","alt_text":"icon"}]},
+				{"type":"image","image_url":"https://x.com/c.png","alt_text":"chart","title":{"type":"plain_text","text":"Weekly chart"}},
+				{"type":"actions","elements":[{"type":"button","text":{"type":"plain_text","text":"Approve"},"action_id":"a"}]}
+			]}`,
+			"*Deploy &lt;prod&gt;*\n*Status*: ok <@U1>\n*Env*\nprod\na &amp; b\n---\nby <@U1> icon\n(画像: Weekly chart)",
+		},
+		{
+			"link unfurl",
+			`{"text":"see link","attachments":[{"title":"Example","title_link":"https://x.com","text":"desc","footer":"x.com"}]}`,
+			"see link\n\n*<https://x.com|Example>*\ndesc\nx.com",
+		},
+		{
+			"legacy attachment",
+			`{"text":"alert","attachments":[{"pretext":"Heads up","author_name":"CI","title":"Build failed",
+				"fields":[{"title":"Branch","value":"main"},{"value":"no title"}],"fallback":"fb"}]}`,
+			"alert\n\nHeads up\nCI\n*Build failed*\n*Branch*: main\nno title",
+		},
+		{
+			"fallback only",
+			`{"text":"","attachments":[{"fallback":"only fallback"}]}`,
+			"only fallback",
+		},
+		{
+			"shared message",
+			`{"text":"fyi","attachments":[{"ts":"1700000000.000100","author_id":"U1","text":"original\nmessage",
+				"from_url":"https://ws.slack.com/archives/C1/p1700000000000100"}]}`,
+			"fyi\n\n&gt; [共有メッセージ] @taro (2023-11-15 07:13)\n&gt; original\n&gt; message\n&gt; 元の投稿: https://ws.slack.com/archives/C1/p1700000000000100",
+		},
+		{
+			"shared message from another workspace with blocks",
+			`{"text":"fyi","attachments":[{"ts":"1700000000.000100","author_name":"Ext User","author_subname":"ext",
+				"blocks":[{"type":"rich_text","elements":[{"type":"rich_text_section","elements":[{"type":"text","text":"from blocks"}]}]}]}]}`,
+			"fyi\n\n&gt; [共有メッセージ] @ext (2023-11-15 07:13)\n&gt; from blocks",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var msg slack.Message
+			if err := json.Unmarshal([]byte(tt.message), &msg); err != nil {
+				t.Fatal(err)
+			}
+			if got := f.messageBody(msg, DefaultLocation()); got != tt.want {
+				t.Errorf("messageBody() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
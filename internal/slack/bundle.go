@@ -0,0 +1,142 @@
+package slack
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shellme/slack-tool/internal/i18n"
+)
+
+// DocumentUser is an entry in the user legend of a bundle
+type DocumentUser struct {
+	UserID   string `json:"user_id,omitempty"`
+	BotID    string `json:"bot_id,omitempty"`
+	Author   string `json:"author"`
+	Name     string `json:"name,omitempty"` // 表示名または氏名
+	IsBot    bool   `json:"is_bot,omitempty"`
+	Messages int    `json:"messages"` // 投稿数
+}
+
+// BuildBundle combines documents from several sources into one bundle document.
+// Messages already contained in an earlier section are removed, sections left empty are dropped,
+// and a user legend is built from all sections. It also returns the number of removed duplicates.
+func BuildBundle(sections []*Document) (*Document, int, error) {
+	if len(sections) == 0 {
+		return nil, 0, errors.New(i18n.T("メッセージがありません"))
+	}
+
+	bundle := &Document{
+		Kind:      KindBundle,
+		Title:     i18n.T("Slackコンテキストバンドル"),
+		FetchedAt: time.Now().In(sections[0].FetchedAt.Location()),
+		Messages:  []DocumentMessage{},
+	}
+
+	seen := make(map[string]bool)
+	removed := 0
+	for _, section := range sections {
+		deduped := *section
+		deduped.Messages, removed = dedupeDocumentMessages(section.Messages, section.ChannelID, seen, removed)
+		if len(deduped.Messages) > 0 {
+			bundle.Sections = append(bundle.Sections, &deduped)
+		}
+	}
+	bundle.Users = documentUsers(bundle.AllMessages())
+
+	return bundle, removed, nil
+}
+
+// dedupeDocumentMessages removes messages already in seen; replies of a removed parent are kept at the top level
+func dedupeDocumentMessages(messages []DocumentMessage, channelID string, seen map[string]bool, removed int) ([]DocumentMessage, int) {
+	var result []DocumentMessage
+	for _, msg := range messages {
+		var replies []DocumentMessage
+		replies, removed = dedupeDocumentMessages(msg.Replies, channelID, seen, removed)
+
+		key := channelID + ":" + msg.Timestamp
+		if seen[key] {
+			removed++
+			result = append(result, replies...)
+			continue
+		}
+		seen[key] = true
+		msg.Replies = replies
+		result = append(result, msg)
+	}
+	return result, removed
+}
+
+// documentUsers lists the authors of messages in order of first appearance with their message counts
+func documentUsers(messages []DocumentMessage) []DocumentUser {
+	index := make(map[string]int)
+	var users []DocumentUser
+	for _, msg := range messages {
+		if msg.Deleted || msg.System {
+			continue
+		}
+		key := msg.UserID + "/" + msg.BotID + "/" + msg.Author
+		if i, exists := index[key]; exists {
+			users[i].Messages++
+			continue
+		}
+		index[key] = len(users)
+		users = append(users, DocumentUser{
+			UserID:   msg.UserID,
+			BotID:    msg.BotID,
+			Author:   msg.Author,
+			Name:     msg.AuthorName,
+			IsBot:    msg.IsBot,
+			Messages: 1,
+		})
+	}
+	return users
+}
+
+// SectionTitle returns a title describing the document as a section of a bundle,
+// e.g. "#general のスレッド: @alice (2024-01-01 10:00:00)"
+func (d *Document) SectionTitle() string {
+	channel := d.Channel
+	if channel == "" {
+		channel = d.ChannelID
+	}
+	if len(d.Messages) == 0 {
+		return "#" + channel
+	}
+
+	first := d.Messages[0]
+	switch {
+	case d.Kind == KindChannel:
+		last := d.Messages[len(d.Messages)-1]
+		return fmt.Sprintf(i18n.T("#%s（%s 〜 %s）"), channel, first.TimeText, last.TimeText)
+	case len(d.Messages) == 1 && len(first.Replies) == 0:
+		return fmt.Sprintf(i18n.T("#%s のメッセージ: %s (%s)"), channel, first.Author, first.TimeText)
+	default:
+		return fmt.Sprintf(i18n.T("#%s のスレッド: %s (%s)"), channel, first.Author, first.TimeText)
+	}
+}
+
+// MessageCount returns the number of messages including replies
+func (d *Document) MessageCount() int {
+	return len(d.AllMessages())
+}
+
+// userLegend returns the details shown for a user in the legend, e.g. "Alice Smith / U123 / 5件"
+func userLegend(user DocumentUser) string {
+	var parts []string
+	if user.Name != "" && user.Name != user.Author && "@"+user.Name != user.Author {
+		parts = append(parts, user.Name)
+	}
+	if user.UserID != "" {
+		parts = append(parts, user.UserID)
+	} else if user.BotID != "" {
+		parts = append(parts, user.BotID)
+	}
+	parts = append(parts, fmt.Sprintf(i18n.T("%d件"), user.Messages))
+
+	result := parts[0]
+	for _, part := range parts[1:] {
+		result += " / " + part
+	}
+	return result
+}
@@ -0,0 +1,84 @@
+package slack
+
+import (
+	"reflect"
+	"testing"
+)
+
+// documentMessage returns a message by a user for bundle tests
+func documentMessage(ts, userID, author string, replies ...DocumentMessage) DocumentMessage {
+	return DocumentMessage{Timestamp: ts, UserID: userID, Author: author, Replies: replies}
+}
+
+func TestBuildBundle(t *testing.T) {
+	thread := &Document{Kind: KindThread, ChannelID: "C1", Messages: []DocumentMessage{
+		documentMessage("100.000001", "U1", "@alice",
+			documentMessage("100.000002", "U2", "@bob"),
+			documentMessage("100.000003", "U1", "@alice"),
+		),
+	}}
+	// 親と返信の一部は前のセクションと重複し、新しい返信はトップレベルに残す
+	channel := &Document{Kind: KindChannel, ChannelID: "C1", Messages: []DocumentMessage{
+		documentMessage("100.000001", "U1", "@alice",
+			documentMessage("100.000002", "U2", "@bob"),
+			documentMessage("100.000004", "U2", "@bob"),
+		),
+		documentMessage("200.000001", "U1", "@alice"),
+		{Timestamp: "300.000001", Author: "@alice", UserID: "U1", System: true},
+	}}
+	// 別のチャンネルの同じタイムスタンプは重複ではない
+	other := &Document{Kind: KindMessage, ChannelID: "C2", Messages: []DocumentMessage{
+		documentMessage("100.000001", "U2", "@bob"),
+		{Timestamp: "100.000002", Author: "@deploy", BotID: "B1", AuthorName: "Deploy", IsBot: true},
+		{Timestamp: "100.000003", Author: "@carol", UserID: "U3", Deleted: true},
+	}}
+	// すべて重複するセクションは出力しない
+	duplicate := &Document{Kind: KindMessage, ChannelID: "C1", Messages: []DocumentMessage{
+		documentMessage("200.000001", "U1", "@alice"),
+	}}
+
+	bundle, removed, err := BuildBundle([]*Document{thread, channel, other, duplicate})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 3 {
+		t.Errorf("removed = %d, want 3", removed)
+	}
+	if bundle.Kind != KindBundle {
+		t.Errorf("Kind = %q, want %q", bundle.Kind, KindBundle)
+	}
+
+	var sections [][]string
+	for _, section := range bundle.Sections {
+		sections = append(sections, documentTimestamps(section))
+	}
+	wantSections := [][]string{
+		{"100.000001", "100.000001>100.000002", "100.000001>100.000003"},
+		{"100.000004", "200.000001", "300.000001"},
+		{"100.000001", "100.000002", "100.000003"},
+	}
+	if !reflect.DeepEqual(sections, wantSections) {
+		t.Errorf("sections = %v, want %v", sections, wantSections)
+	}
+
+	// 削除済み・システムメッセージは一覧に含めない
+	wantUsers := []DocumentUser{
+		{UserID: "U1", Author: "@alice", Messages: 3},
+		{UserID: "U2", Author: "@bob", Messages: 3},
+		{BotID: "B1", Author: "@deploy", Name: "Deploy", IsBot: true, Messages: 1},
+	}
+	if !reflect.DeepEqual(bundle.Users, wantUsers) {
+		t.Errorf("Users = %+v, want %+v", bundle.Users, wantUsers)
+	}
+
+	// 入力のセクションは変更しない
+	if got := documentTimestamps(channel); len(got) != 5 {
+		t.Errorf("input section was modified: %v", got)
+	}
+}
+
+func TestBuildBundleEmpty(t *testing.T) {
+	if _, _, err := BuildBundle(nil); err == nil {
+		t.Error("BuildBundle(nil) succeeded, want error")
+	}
+}
@@ -0,0 +1,250 @@
+package slack
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shellme/slack-tool/internal/i18n"
+)
+
+// TrimStrategy decides which messages are removed to fit a document in a token budget
+type TrimStrategy string
+
+const (
+	// TrimOldest removes the oldest threads first
+	TrimOldest TrimStrategy = "oldest"
+	// TrimLongestReplies removes the longest thread replies first, then the oldest threads
+	TrimLongestReplies TrimStrategy = "longest-replies"
+)
+
+// TrimStrategies lists the accepted trim strategies
+var TrimStrategies = []string{string(TrimOldest), string(TrimLongestReplies)}
+
+// ParseTrimStrategy parses a trim strategy name
+func ParseTrimStrategy(value string) (TrimStrategy, error) {
+	switch strategy := TrimStrategy(strings.ToLower(strings.TrimSpace(value))); strategy {
+	case TrimOldest, TrimLongestReplies:
+		return strategy, nil
+	}
+	return "", fmt.Errorf(i18n.T("サポートされていない削減方法です: %s（%s のいずれかを指定してください）"), value, strings.Join(TrimStrategies, ", "))
+}
+
+// SplitDocument splits a document into parts that fit in maxTokens when rendered by r.
+// Top-level messages are kept together with their replies, and each part starts with the last
+// overlap threads of the previous part as context. A thread that alone exceeds maxTokens forms its own part.
+func SplitDocument(doc *Document, r Renderer, maxTokens, overlap int) ([]*Document, error) {
+	budget, err := newTokenBudget(doc, r)
+	if err != nil {
+		return nil, err
+	}
+
+	var parts []*Document
+	previous := 0
+	for start := 0; start < len(doc.Messages); {
+		// 前のパートの末尾のスレッドを文脈として含める（新しいスレッドが1つも入らない場合は減らす）
+		context := max(previous, start-max(overlap, 0))
+		if len(parts) == 0 {
+			context = start
+		}
+		for context < start && budget.overhead+budget.sum(context, start+1) > maxTokens {
+			context++
+		}
+
+		end := start + 1
+		for end < len(doc.Messages) && budget.overhead+budget.sum(context, end+1) <= maxTokens {
+			end++
+		}
+
+		part := *doc
+		part.Messages = doc.Messages[context:end]
+		parts = append(parts, &part)
+		previous, start = start, end
+	}
+
+	if len(parts) > 1 {
+		for i, part := range parts {
+			part.Part, part.Parts = i+1, len(parts)
+			// 統計情報は全体のものなので最初のパートにのみ含める
+			if i > 0 {
+				part.Stats = nil
+			}
+		}
+	}
+	return parts, nil
+}
+
+// TrimDocument removes messages by strategy until the document fits in maxTokens when rendered by r.
+// It returns the trimmed document and the number of removed messages; the last thread's parent is always kept.
+func TrimDocument(doc *Document, r Renderer, maxTokens int, strategy TrimStrategy) (*Document, int, error) {
+	_, total, err := RenderTokens(r, doc)
+	if err != nil || total <= maxTokens {
+		return doc, 0, err
+	}
+
+	budget, err := newTokenBudget(doc, r)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	removedUnits := make([]bool, len(doc.Messages))
+	removedReplies := make([][]bool, len(doc.Messages))
+	remaining := make([]int, len(doc.Messages))
+	for i, msg := range doc.Messages {
+		removedReplies[i] = make([]bool, len(msg.Replies))
+		remaining[i] = budget.units[i]
+	}
+
+	trimmed := doc
+	removed := 0
+	for _, op := range trimOrder(doc, budget, strategy) {
+		if removedUnits[op.unit] {
+			continue
+		}
+		if op.reply < 0 {
+			removedUnits[op.unit] = true
+			removed += 1 + countRemaining(removedReplies[op.unit])
+			total -= remaining[op.unit]
+		} else {
+			removedReplies[op.unit][op.reply] = true
+			removed++
+			total -= budget.replies[op.unit][op.reply]
+			remaining[op.unit] -= budget.replies[op.unit][op.reply]
+		}
+		if total > maxTokens {
+			continue
+		}
+
+		// 見積もりが上限に収まったら実際に出力して確認
+		trimmed = buildTrimmedDocument(doc, removedUnits, removedReplies)
+		if _, total, err = RenderTokens(r, trimmed); err != nil {
+			return nil, 0, err
+		}
+		if total <= maxTokens {
+			return trimmed, removed, nil
+		}
+	}
+
+	return buildTrimmedDocument(doc, removedUnits, removedReplies), removed, nil
+}
+
+// tokenBudget holds estimated token counts of the parts of a rendered document
+type tokenBudget struct {
+	overhead int     // ヘッダー・フッターなどメッセージ以外
+	units    []int   // トップレベルのメッセージ（返信を含む）
+	replies  [][]int // 各返信
+}
+
+// newTokenBudget estimates token counts by rendering each thread alone
+func newTokenBudget(doc *Document, r Renderer) (*tokenBudget, error) {
+	base := *doc
+	base.Messages = nil
+	_, overhead, err := RenderTokens(r, &base)
+	if err != nil {
+		return nil, err
+	}
+
+	budget := &tokenBudget{overhead: overhead}
+	for _, msg := range doc.Messages {
+		base.Messages = []DocumentMessage{msg}
+		_, tokens, err := RenderTokens(r, &base)
+		if err != nil {
+			return nil, err
+		}
+		budget.units = append(budget.units, max(tokens-overhead, 1))
+
+		// 返信は親メッセージのみの場合との差分
+		parent := msg
+		parent.Replies = nil
+		base.Messages = []DocumentMessage{parent}
+		_, parentTokens, err := RenderTokens(r, &base)
+		if err != nil {
+			return nil, err
+		}
+		var replies []int
+		for _, reply := range msg.Replies {
+			parent.Replies = []DocumentMessage{reply}
+			base.Messages = []DocumentMessage{parent}
+			_, tokens, err := RenderTokens(r, &base)
+			if err != nil {
+				return nil, err
+			}
+			replies = append(replies, max(tokens-parentTokens, 1))
+		}
+		budget.replies = append(budget.replies, replies)
+	}
+	return budget, nil
+}
+
+// sum returns the estimated tokens of the top-level messages in [from, to)
+func (b *tokenBudget) sum(from, to int) int {
+	total := 0
+	for _, tokens := range b.units[from:to] {
+		total += tokens
+	}
+	return total
+}
+
+// trimOperation removes a whole thread (reply < 0) or a single reply
+type trimOperation struct {
+	unit, reply int
+}
+
+// trimOrder returns the order in which messages are removed for a strategy
+func trimOrder(doc *Document, budget *tokenBudget, strategy TrimStrategy) []trimOperation {
+	var ops []trimOperation
+	last := len(doc.Messages) - 1
+
+	if strategy == TrimLongestReplies {
+		for i, msg := range doc.Messages {
+			for j := range msg.Replies {
+				ops = append(ops, trimOperation{i, j})
+			}
+		}
+		sort.SliceStable(ops, func(a, b int) bool {
+			return budget.replies[ops[a].unit][ops[a].reply] > budget.replies[ops[b].unit][ops[b].reply]
+		})
+	}
+
+	// 古いスレッドから削除し、最後のスレッドは古い返信から削除
+	for i := 0; i < last; i++ {
+		ops = append(ops, trimOperation{i, -1})
+	}
+	if last >= 0 && strategy != TrimLongestReplies {
+		for j := range doc.Messages[last].Replies {
+			ops = append(ops, trimOperation{last, j})
+		}
+	}
+	return ops
+}
+
+// buildTrimmedDocument copies a document without the removed messages
+func buildTrimmedDocument(doc *Document, removedUnits []bool, removedReplies [][]bool) *Document {
+	trimmed := *doc
+	trimmed.Messages = nil
+	for i, msg := range doc.Messages {
+		if removedUnits[i] {
+			continue
+		}
+		replies := msg.Replies
+		msg.Replies = nil
+		for j, reply := range replies {
+			if !removedReplies[i][j] {
+				msg.Replies = append(msg.Replies, reply)
+			}
+		}
+		trimmed.Messages = append(trimmed.Messages, msg)
+	}
+	return &trimmed
+}
+
+// countRemaining returns the number of replies not yet removed
+func countRemaining(removed []bool) int {
+	count := 0
+	for _, r := range removed {
+		if !r {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,162 @@
+package slack
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// lineRenderer renders one line per message so that token counts are easy to follow:
+// the header is one token and each message as many tokens as its text has words
+type lineRenderer struct{}
+
+func (lineRenderer) Render(w io.Writer, doc *Document) error {
+	fmt.Fprintln(w, "head")
+	for _, msg := range doc.Messages {
+		fmt.Fprintln(w, msg.Text)
+		for _, reply := range msg.Replies {
+			fmt.Fprintln(w, "  "+reply.Text)
+		}
+	}
+	return nil
+}
+
+// tokenMessage builds a message whose text is estimated at tokens tokens
+func tokenMessage(timestamp string, tokens int, replies ...DocumentMessage) DocumentMessage {
+	return DocumentMessage{Timestamp: timestamp, Text: strings.TrimSpace(strings.Repeat("word ", tokens)), Replies: replies}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"word", 1},
+		{"words", 2},
+		{"hello, world", 5},
+		{"  spaced\n\tout  ", 3},
+		{"こんにちは", 5},
+		{"日本", 3},
+		{"日本語", 5},
+		{"Go言語", 4},
+	}
+
+	for _, tt := range tests {
+		if got := EstimateTokens(tt.text); got != tt.want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSplitDocument(t *testing.T) {
+	tests := []struct {
+		name      string
+		messages  []DocumentMessage
+		maxTokens int
+		overlap   int
+		want      [][]string
+	}{
+		{
+			"fits in one part",
+			[]DocumentMessage{tokenMessage("1", 3), tokenMessage("2", 3)},
+			10, 0,
+			[][]string{{"1", "2"}},
+		},
+		{
+			"without overlap",
+			[]DocumentMessage{tokenMessage("1", 3), tokenMessage("2", 3), tokenMessage("3", 3), tokenMessage("4", 3)},
+			7, 0,
+			[][]string{{"1", "2"}, {"3", "4"}},
+		},
+		{
+			"with overlap",
+			[]DocumentMessage{tokenMessage("1", 3), tokenMessage("2", 3), tokenMessage("3", 3), tokenMessage("4", 3)},
+			7, 1,
+			[][]string{{"1", "2"}, {"2", "3"}, {"3", "4"}},
+		},
+		{
+			"replies stay with their parent",
+			[]DocumentMessage{tokenMessage("1", 1, tokenMessage("1.1", 2), tokenMessage("1.2", 2)), tokenMessage("2", 2)},
+			6, 0,
+			[][]string{{"1", "1>1.1", "1>1.2"}, {"2"}},
+		},
+		{
+			"oversized thread forms its own part",
+			[]DocumentMessage{tokenMessage("1", 2), tokenMessage("2", 10), tokenMessage("3", 2)},
+			5, 1,
+			[][]string{{"1"}, {"2"}, {"3"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := &Document{Messages: tt.messages, Stats: &DocumentStats{}}
+			parts, err := SplitDocument(doc, lineRenderer{}, tt.maxTokens, tt.overlap)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got [][]string
+			for i, part := range parts {
+				got = append(got, documentTimestamps(part))
+				if len(parts) > 1 && (part.Part != i+1 || part.Parts != len(parts)) {
+					t.Errorf("part %d numbered %d/%d", i+1, part.Part, part.Parts)
+				}
+				if (part.Stats != nil) != (i == 0) {
+					t.Errorf("part %d has stats: %v", i+1, part.Stats != nil)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitDocument() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrimDocument(t *testing.T) {
+	// 古い順: 1（3）、2（3）、3（親 1 + 返信 2・2）、見出し 1 の合計 12 トークン
+	oldest := []DocumentMessage{
+		tokenMessage("1", 3),
+		tokenMessage("2", 3),
+		tokenMessage("3", 1, tokenMessage("3.1", 2), tokenMessage("3.2", 2)),
+	}
+	// 1（親 1 + 返信 4）、2（親 1 + 返信 1・2）、3（3）、見出し 1 の合計 13 トークン
+	longest := []DocumentMessage{
+		tokenMessage("1", 1, tokenMessage("1.1", 4)),
+		tokenMessage("2", 1, tokenMessage("2.1", 1), tokenMessage("2.2", 2)),
+		tokenMessage("3", 3),
+	}
+
+	tests := []struct {
+		name        string
+		messages    []DocumentMessage
+		maxTokens   int
+		strategy    TrimStrategy
+		want        []string
+		wantRemoved int
+	}{
+		{"within budget", oldest, 12, TrimOldest, []string{"1", "2", "3", "3>3.1", "3>3.2"}, 0},
+		{"oldest threads first", oldest, 7, TrimOldest, []string{"3", "3>3.1", "3>3.2"}, 2},
+		{"oldest replies of the last thread", oldest, 4, TrimOldest, []string{"3", "3>3.2"}, 3},
+		{"last parent is kept", oldest, 1, TrimOldest, []string{"3"}, 4},
+		{"longest reply first", longest, 9, TrimLongestReplies, []string{"1", "2", "2>2.1", "2>2.2", "3"}, 1},
+		{"all replies before threads", longest, 6, TrimLongestReplies, []string{"1", "2", "3"}, 3},
+		{"then oldest threads", longest, 4, TrimLongestReplies, []string{"3"}, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := &Document{Messages: tt.messages}
+			trimmed, removed, err := TrimDocument(doc, lineRenderer{}, tt.maxTokens, tt.strategy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := documentTimestamps(trimmed); !reflect.DeepEqual(got, tt.want) || removed != tt.wantRemoved {
+				t.Errorf("TrimDocument() = %v (%d removed), want %v (%d removed)", got, removed, tt.want, tt.wantRemoved)
+			}
+		})
+	}
+}
@@ -1,23 +1,36 @@
 package slack
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/shellme/slack-tool/internal/i18n"
 	"github.com/slack-go/slack"
 )
 
 // Client wraps the Slack API client
 type Client struct {
-	api *slack.Client
+	api      *slack.Client
+	location *time.Location // 日時指定（--oldest/--latest）の解析に使用するタイムゾーン
+	teamURL  string         // ワークスペースのURL（TestConnection で取得、パーマリンクの生成に使用）
 }
 
 // NewClient creates a new Slack client
 func NewClient(token string) *Client {
 	api := slack.New(token)
-	return &Client{api: api}
+	return &Client{api: api, location: DefaultLocation()}
+}
+
+// SetLocation sets the time zone used to parse date ranges
+func (c *Client) SetLocation(loc *time.Location) {
+	if loc == nil {
+		loc = DefaultLocation()
+	}
+	c.location = loc
 }
 
 // GetThreadReplies fetches all replies in a thread
@@ -47,6 +60,16 @@ func (c *Client) GetUserInfo(userID string) (*slack.User, error) {
 	return user, nil
 }
 
+// GetBotInfo fetches bot information by bot ID
+func (c *Client) GetBotInfo(botID string) (*slack.Bot, error) {
+	bot, err := c.api.GetBotInfo(slack.GetBotInfoParameters{Bot: botID})
+	if err != nil {
+		return nil, c.handleAPIError(err)
+	}
+
+	return bot, nil
+}
+
 // GetUserGroups fetches all user groups (subteams) information
 func (c *Client) GetUserGroups() ([]slack.UserGroup, error) {
 	usergroups, err := c.api.GetUserGroups()
@@ -88,14 +111,14 @@ func (c *Client) GetChannelHistoryWithThreadsInRange(channelID string, limit int
 	if oldest != "" {
 		oldestTS, err := c.parseTimestamp(oldest)
 		if err != nil {
-			return nil, fmt.Errorf("oldest の日時解析に失敗しました: %v", err)
+			return nil, fmt.Errorf(i18n.T("oldest の日時解析に失敗しました: %v"), err)
 		}
 		params.Oldest = oldestTS
 	}
 	if latest != "" {
 		latestTS, err := c.parseTimestamp(latest)
 		if err != nil {
-			return nil, fmt.Errorf("latest の日時解析に失敗しました: %v", err)
+			return nil, fmt.Errorf(i18n.T("latest の日時解析に失敗しました: %v"), err)
 		}
 		params.Latest = latestTS
 	}
@@ -172,13 +195,17 @@ func (c *Client) PostMessageWithOptions(channelID, text string, options ...slack
 }
 
 // PostThreadReply posts a reply to a thread
-func (c *Client) PostThreadReply(channelID, text, threadTimestamp string) error {
+func (c *Client) PostThreadReply(channelID, text, threadTimestamp string, options ...slack.MsgOption) error {
 	// Slack API ドキュメントに基づく正しい実装
-	_, _, err := c.api.PostMessage(channelID,
+	msgOptions := []slack.MsgOption{
 		slack.MsgOptionText(text, false),
 		slack.MsgOptionPostMessageParameters(slack.PostMessageParameters{
 			ThreadTimestamp: threadTimestamp,
-		}))
+		}),
+	}
+	msgOptions = append(msgOptions, options...)
+
+	_, _, err := c.api.PostMessage(channelID, msgOptions...)
 	if err != nil {
 		return c.handleAPIError(err)
 	}
@@ -186,21 +213,21 @@ func (c *Client) PostThreadReply(channelID, text, threadTimestamp string) error
 }
 
 // PostThreadReplyByURL posts a reply to a thread using a thread URL
-func (c *Client) PostThreadReplyByURL(text, threadURL string) error {
+func (c *Client) PostThreadReplyByURL(text, threadURL string, options ...slack.MsgOption) error {
 	// スレッドURLを解析
 	threadInfo, err := ParseThreadURL(threadURL)
 	if err != nil {
-		return fmt.Errorf("スレッドURLの解析に失敗しました: %v", err)
+		return fmt.Errorf(i18n.T("スレッドURLの解析に失敗しました: %v"), err)
 	}
 
 	// メッセージ情報を取得して正確なタイムスタンプを確認
 	msg, err := c.GetMessageInfo(threadInfo.ChannelID, threadInfo.Timestamp)
 	if err != nil {
-		return fmt.Errorf("メッセージ情報の取得に失敗しました: %v", err)
+		return fmt.Errorf(i18n.T("メッセージ情報の取得に失敗しました: %v"), err)
 	}
 
 	// 正確なタイムスタンプでスレッド返信
-	return c.PostThreadReply(threadInfo.ChannelID, text, msg.Timestamp)
+	return c.PostThreadReply(threadInfo.ChannelID, text, msg.Timestamp, options...)
 }
 
 // GetMessageInfo gets message information by timestamp
@@ -244,7 +271,114 @@ func (c *Client) GetMessageInfo(channelID, timestamp string) (*slack.Message, er
 		}
 	}
 
-	return nil, fmt.Errorf("指定されたタイムスタンプのメッセージが見つかりませんでした: %s", timestamp)
+	return nil, fmt.Errorf(i18n.T("指定されたタイムスタンプのメッセージが見つかりませんでした: %s"), timestamp)
+}
+
+// GetMessageContext fetches a message with up to before/after messages around it, oldest first.
+// For a thread reply (threadTimestamp set) the surrounding replies are returned; otherwise the surrounding channel messages.
+func (c *Client) GetMessageContext(channelID, timestamp, threadTimestamp string, before, after int) ([]slack.Message, error) {
+	if threadTimestamp != "" && threadTimestamp != timestamp {
+		replies, err := c.GetThreadReplies(channelID, threadTimestamp)
+		if err != nil {
+			return nil, err
+		}
+		return contextWindow(replies, timestamp, before, after)
+	}
+
+	// 対象のメッセージとそれより前のメッセージ（新しい順に返される）
+	olderParams := &slack.GetConversationHistoryParameters{
+		ChannelID: channelID,
+		Latest:    timestamp,
+		Inclusive: true,
+		Limit:     before + 1,
+	}
+	older, err := c.api.GetConversationHistory(olderParams)
+	if err != nil {
+		return nil, c.handleAPIError(err)
+	}
+	messages := older.Messages
+
+	if after > 0 {
+		newer, err := c.newerMessages(channelID, timestamp, after)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, newer...)
+	}
+
+	return contextWindow(messages, timestamp, before, after)
+}
+
+// newerMessages fetches the channel messages posted after timestamp, at least limit of them when there are that many.
+// conversations.history returns the newest messages of a range first, so the range is bounded with latest
+// and widened until it holds limit messages or reaches the present.
+func (c *Client) newerMessages(channelID, timestamp string, limit int) ([]slack.Message, error) {
+	seconds, _, _ := strings.Cut(timestamp, ".")
+	start, err := strconv.ParseInt(seconds, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("無効なタイムスタンプです: %s"), timestamp)
+	}
+
+	for window := int64(time.Hour / time.Second); ; window *= 4 {
+		// 範囲の終わりが現在を過ぎたら latest を指定せずに最後まで取得
+		latest := ""
+		if end := start + window; end < time.Now().Unix() {
+			latest = strconv.FormatInt(end, 10)
+		}
+		messages, err := c.historyInRange(channelID, timestamp, latest)
+		if err != nil {
+			return nil, err
+		}
+		if len(messages) >= limit || latest == "" {
+			return messages, nil
+		}
+	}
+}
+
+// historyInRange fetches all channel messages between oldest and latest (both exclusive), following the cursor
+func (c *Client) historyInRange(channelID, oldest, latest string) ([]slack.Message, error) {
+	var messages []slack.Message
+	cursor := ""
+	for {
+		history, err := c.api.GetConversationHistory(&slack.GetConversationHistoryParameters{
+			ChannelID: channelID,
+			Oldest:    oldest,
+			Latest:    latest,
+			Cursor:    cursor,
+			Limit:     200,
+		})
+		if err != nil {
+			return nil, c.handleAPIError(err)
+		}
+		messages = append(messages, history.Messages...)
+
+		if !history.HasMore || history.ResponseMetaData.NextCursor == "" {
+			break
+		}
+		cursor = history.ResponseMetaData.NextCursor
+	}
+	return messages, nil
+}
+
+// contextWindow returns the message with the given timestamp and up to before/after messages around it, oldest first.
+// messages may be in any order and contain duplicates; an error is returned when the message is not among them.
+func contextWindow(messages []slack.Message, timestamp string, before, after int) ([]slack.Message, error) {
+	sorted := make([]slack.Message, 0, len(messages))
+	seen := make(map[string]bool)
+	for _, msg := range messages {
+		if !seen[msg.Timestamp] {
+			seen[msg.Timestamp] = true
+			sorted = append(sorted, msg)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return timestampLess(sorted[i].Timestamp, sorted[j].Timestamp) })
+
+	for i, msg := range sorted {
+		if msg.Timestamp == timestamp {
+			return sorted[max(i-before, 0):min(i+after+1, len(sorted))], nil
+		}
+	}
+	return nil, fmt.Errorf(i18n.T("指定されたタイムスタンプのメッセージが見つかりませんでした: %s"), timestamp)
 }
 
 // ReactionInfo contains information about a reaction
@@ -255,9 +389,10 @@ type ReactionInfo struct {
 
 // UserInfo contains basic user information
 type UserInfo struct {
-	ID    string `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	RealName string `json:"real_name"`
+	Email    string `json:"email"`
 }
 
 // GetReactions gets reactions for a specific message
@@ -265,13 +400,13 @@ func (c *Client) GetReactions(messageURL string) ([]ReactionInfo, error) {
 	// メッセージURLを解析
 	threadInfo, err := ParseThreadURL(messageURL)
 	if err != nil {
-		return nil, fmt.Errorf("メッセージURLの解析に失敗しました: %v", err)
+		return nil, fmt.Errorf(i18n.T("メッセージURLの解析に失敗しました: %v"), err)
 	}
 
 	// メッセージ情報を取得
 	_, err = c.GetMessageInfo(threadInfo.ChannelID, threadInfo.Timestamp)
 	if err != nil {
-		return nil, fmt.Errorf("メッセージ情報の取得に失敗しました: %v", err)
+		return nil, fmt.Errorf(i18n.T("メッセージ情報の取得に失敗しました: %v"), err)
 	}
 
 	// リアクション情報を取得
@@ -297,9 +432,10 @@ func (c *Client) GetReactions(messageURL string) ([]ReactionInfo, error) {
 				continue
 			}
 			users = append(users, UserInfo{
-				ID:    user.ID,
-				Name:  user.Name,
-				Email: user.Profile.Email,
+				ID:       user.ID,
+				Name:     user.Name,
+				RealName: user.RealName,
+				Email:    user.Profile.Email,
 			})
 		}
 
@@ -312,6 +448,101 @@ func (c *Client) GetReactions(messageURL string) ([]ReactionInfo, error) {
 	return reactionInfos, nil
 }
 
+// GetChannelMembers gets human members of a channel (bots and deactivated users are excluded)
+func (c *Client) GetChannelMembers(channelID string) ([]UserInfo, error) {
+	// conversations.members APIでメンバーIDを全件取得（ページネーション対応）
+	var memberIDs []string
+	cursor := ""
+	for {
+		ids, nextCursor, err := c.api.GetUsersInConversation(&slack.GetUsersInConversationParameters{
+			ChannelID: channelID,
+			Cursor:    cursor,
+			Limit:     1000,
+		})
+		if err != nil {
+			return nil, c.handleAPIError(err)
+		}
+		memberIDs = append(memberIDs, ids...)
+
+		if nextCursor == "" {
+			break
+		}
+		cursor = nextCursor
+	}
+
+	// users.list でワークスペースのユーザーを一括取得（メンバーごとに users.info を呼ぶとレート制限に達するため）
+	users, err := c.api.GetUsers(slack.GetUsersOptionLimit(1000))
+	if err != nil {
+		return nil, c.handleAPIError(err)
+	}
+	usersByID := make(map[string]*slack.User, len(users))
+	for i := range users {
+		usersByID[users[i].ID] = &users[i]
+	}
+
+	var members []UserInfo
+	for _, userID := range memberIDs {
+		user, ok := usersByID[userID]
+		if !ok {
+			// 外部組織のユーザー（Slackコネクト）は users.list に含まれないため個別に取得
+			if user, err = c.GetUserInfo(userID); err != nil {
+				// ユーザー情報が取得できない場合はスキップ
+				continue
+			}
+		}
+
+		// ボット・アプリ・無効化されたユーザーは除外
+		if user.IsBot || user.IsAppUser || user.Deleted || user.ID == "USLACKBOT" {
+			continue
+		}
+
+		members = append(members, UserInfo{
+			ID:       user.ID,
+			Name:     user.Name,
+			RealName: user.RealName,
+			Email:    user.Profile.Email,
+		})
+	}
+
+	return members, nil
+}
+
+// SearchMessagePermalinks searches messages and returns their permalinks in chronological order
+func (c *Client) SearchMessagePermalinks(query string, limit int) ([]string, error) {
+	var permalinks []string
+	page := 1
+	for {
+		// search.messages APIを呼び出し（古い順）
+		result, err := c.api.SearchMessages(query, slack.SearchParameters{
+			Sort:          "timestamp",
+			SortDirection: "asc",
+			Count:         100,
+			Page:          page,
+		})
+		if err != nil {
+			// search.messages は search:read スコープを持つUser Tokenでのみ使用可能（Bot Tokenは不可）
+			if contains(err.Error(), "not_allowed_token_type") || contains(err.Error(), "missing_scope") {
+				return nil, errors.New(i18n.T("メッセージの検索には search:read スコープを持つUser Token（xoxp-）が必要です"))
+			}
+			return nil, c.handleAPIError(err)
+		}
+
+		for _, match := range result.Matches {
+			permalinks = append(permalinks, match.Permalink)
+			if limit > 0 && len(permalinks) >= limit {
+				return permalinks, nil
+			}
+		}
+
+		if page >= result.Paging.Pages {
+			break
+		}
+		page++
+	}
+
+	return permalinks, nil
+}
+
 // handleAPIError converts Slack API errors to user-friendly messages
 func (c *Client) handleAPIError(err error) error {
 	if err == nil {
@@ -323,27 +554,27 @@ func (c *Client) handleAPIError(err error) error {
 
 	switch {
 	case contains(errStr, "invalid_auth"):
-		return fmt.Errorf("認証に失敗しました。トークンが無効または期限切れです")
+		return errors.New(i18n.T("認証に失敗しました。トークンが無効または期限切れです"))
 	case contains(errStr, "account_inactive"):
-		return fmt.Errorf("アカウントが無効です")
+		return errors.New(i18n.T("アカウントが無効です"))
 	case contains(errStr, "token_revoked"):
-		return fmt.Errorf("トークンが取り消されました")
+		return errors.New(i18n.T("トークンが取り消されました"))
 	case contains(errStr, "not_authed"):
-		return fmt.Errorf("認証されていません")
+		return errors.New(i18n.T("認証されていません"))
 	case contains(errStr, "channel_not_found"):
-		return fmt.Errorf("チャンネルが見つかりません")
+		return errors.New(i18n.T("チャンネルが見つかりません"))
 	case contains(errStr, "thread_not_found"):
-		return fmt.Errorf("スレッドが見つかりません")
+		return errors.New(i18n.T("スレッドが見つかりません"))
 	case contains(errStr, "not_in_channel"):
-		return fmt.Errorf("このチャンネルにアクセスする権限がありません")
+		return errors.New(i18n.T("このチャンネルにアクセスする権限がありません"))
 	case contains(errStr, "rate_limited"):
-		return fmt.Errorf("APIレート制限に達しました。しばらく待ってから再試行してください")
+		return errors.New(i18n.T("APIレート制限に達しました。しばらく待ってから再試行してください"))
 	case contains(errStr, "timeout"):
-		return fmt.Errorf("ネットワークタイムアウトが発生しました")
+		return errors.New(i18n.T("ネットワークタイムアウトが発生しました"))
 	case contains(errStr, "no_network"):
-		return fmt.Errorf("ネットワーク接続エラーが発生しました")
+		return errors.New(i18n.T("ネットワーク接続エラーが発生しました"))
 	default:
-		return fmt.Errorf("Slack APIエラー: %v", err)
+		return fmt.Errorf(i18n.T("Slack APIエラー: %v"), err)
 	}
 }
 
@@ -367,39 +598,36 @@ func containsSubstring(s, substr string) bool {
 	return false
 }
 
-// parseTimestamp converts various date formats to Slack timestamp format
+// parseTimestamp converts dates, relative expressions and message URLs to Slack timestamp format (see ParseTimeBoundary)
 func (c *Client) parseTimestamp(dateStr string) (string, error) {
-	// 既にUnixタイムスタンプ形式（数字のみ）の場合はそのまま返す
-	if timestamp, err := strconv.ParseFloat(dateStr, 64); err == nil {
-		// Slack APIは秒単位のタイムスタンプを期待する
-		return fmt.Sprintf("%.0f", timestamp), nil
-	}
-
-	// 日時文字列を解析するためのフォーマット一覧
-	formats := []string{
-		"2006-01-02T15:04:05Z07:00", // RFC3339
-		"2006-01-02T15:04:05",       // ローカル時刻
-		"2006-01-02 15:04:05",       // スペース区切り
-		"2006-01-02",                // 日付のみ
-	}
-
-	for _, format := range formats {
-		if t, err := time.Parse(format, dateStr); err == nil {
-			// Unixタイムスタンプに変換（秒単位）
-			return fmt.Sprintf("%.0f", float64(t.Unix())), nil
-		}
-	}
-
-	return "", fmt.Errorf("サポートされていない日時形式です: %s", dateStr)
+	// タイムゾーンの指定がない形式は設定されたタイムゾーンとして解釈
+	return ParseTimeBoundary(dateStr, c.location, time.Now())
 }
 
 // TestConnection tests the Slack API connection
 func (c *Client) TestConnection() error {
 	// auth.test APIを呼び出して接続をテスト
-	_, err := c.api.AuthTest()
+	resp, err := c.api.AuthTest()
 	if err != nil {
 		return c.handleAPIError(err)
 	}
 
+	// パーマリンクの生成用にワークスペースのURLを保存
+	c.teamURL = resp.URL
+
 	return nil
 }
+
+// MessagePermalink builds the permalink of a message (empty if the workspace URL is unknown)
+func (c *Client) MessagePermalink(channelID, timestamp, threadTimestamp string) string {
+	if c.teamURL == "" || channelID == "" || timestamp == "" {
+		return ""
+	}
+
+	// 1234567890.123456 -> p1234567890123456
+	permalink := strings.TrimSuffix(c.teamURL, "/") + "/archives/" + channelID + "/p" + strings.ReplaceAll(timestamp, ".", "")
+	if threadTimestamp != "" && threadTimestamp != timestamp {
+		permalink += "?thread_ts=" + threadTimestamp + "&cid=" + channelID
+	}
+	return permalink
+}
@@ -0,0 +1,68 @@
+package slack
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func TestContextWindow(t *testing.T) {
+	// スレッドの返信（古い順）
+	replies := []slack.Message{
+		testMessage("100.000001", "100.000001", "U1", "parent"),
+		testMessage("100.000002", "100.000001", "U2", "reply 1"),
+		testMessage("100.000003", "100.000001", "U1", "reply 2"),
+		testMessage("100.000004", "100.000001", "U2", "reply 3"),
+		testMessage("100.000005", "100.000001", "U1", "reply 4"),
+	}
+	// チャンネル履歴: 対象以前は新しい順、対象より後は範囲の新しい順で返され、桁数の異なるタイムスタンプを含む
+	channel := []slack.Message{
+		testMessage("1000000000.000001", "", "U1", "target"),
+		testMessage("999999999.000002", "", "U2", "before 1"),
+		testMessage("999999999.000001", "", "U1", "before 2"),
+		testMessage("1000000300.000001", "", "U2", "after 3"),
+		testMessage("1000000200.000001", "", "U1", "after 2"),
+		testMessage("1000000100.000001", "", "U2", "after 1"),
+	}
+
+	tests := []struct {
+		name      string
+		messages  []slack.Message
+		timestamp string
+		before    int
+		after     int
+		want      []string
+		wantErr   bool
+	}{
+		{"reply in the middle", replies, "100.000003", 1, 1, []string{"100.000002", "100.000003", "100.000004"}, false},
+		{"before clipped at the parent", replies, "100.000002", 5, 0, []string{"100.000001", "100.000002"}, false},
+		{"after clipped at the last reply", replies, "100.000004", 0, 5, []string{"100.000004", "100.000005"}, false},
+		{"target only", replies, "100.000003", 0, 0, []string{"100.000003"}, false},
+		{
+			"channel sorted oldest first",
+			channel, "1000000000.000001", 1, 2,
+			[]string{"999999999.000002", "1000000000.000001", "1000000100.000001", "1000000200.000001"},
+			false,
+		},
+		{
+			"duplicates removed",
+			append(channel, channel[0], channel[5]), "1000000000.000001", 0, 1,
+			[]string{"1000000000.000001", "1000000100.000001"},
+			false,
+		},
+		{"target not found", replies, "100.000009", 1, 1, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := contextWindow(tt.messages, tt.timestamp, tt.before, tt.after)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("contextWindow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(timestamps(got), tt.want) {
+				t.Errorf("contextWindow() = %v, want %v", timestamps(got), tt.want)
+			}
+		})
+	}
+}
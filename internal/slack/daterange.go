@@ -0,0 +1,202 @@
+package slack
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shellme/slack-tool/internal/i18n"
+)
+
+// dateLayouts are the absolute date formats accepted by ParseTime
+var dateLayouts = []string{
+	"2006-01-02T15:04:05Z07:00", // RFC3339
+	"2006-01-02T15:04:05",       // タイムゾーンなし
+	"2006-01-02 15:04:05",       // スペース区切り
+	"2006-01-02 15:04",          // 秒なし
+	"2006-01-02",                // 日付のみ
+	"2006/01/02",                // スラッシュ区切り
+}
+
+// weekdays maps English weekday names and abbreviations to time.Weekday
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+var (
+	// relativePattern matches "7d", "12h", "30m", "2w"
+	relativePattern = regexp.MustCompile(`^(\d+)\s*(m|min|h|d|w)$`)
+	// agoPattern matches "3 days ago", "1 week ago"
+	agoPattern = regexp.MustCompile(`^(\d+)\s+(minute|hour|day|week)s?\s+ago$`)
+	// isoWeekPattern matches ISO weeks such as "2024-W18"
+	isoWeekPattern = regexp.MustCompile(`^(\d{4})-?[Ww](\d{1,2})$`)
+)
+
+// ParseTimeBoundary resolves a date expression to a Slack timestamp for the oldest/latest parameters.
+// Besides ParseTime expressions it accepts Unix timestamps and Slack message URLs (the message's own timestamp).
+func ParseTimeBoundary(value string, loc *time.Location, now time.Time) (string, error) {
+	value = strings.TrimSpace(value)
+
+	// Unixタイムスタンプ（小数部のあるSlackのタイムスタンプはそのまま使用）
+	if timestamp, err := strconv.ParseFloat(value, 64); err == nil {
+		if strings.Contains(value, ".") {
+			return value, nil
+		}
+		return fmt.Sprintf("%.0f", timestamp), nil
+	}
+
+	// SlackメッセージのURL（そのメッセージの前後を境界にする）
+	if strings.Contains(value, "://") {
+		info, err := ParseThreadURL(value)
+		if err != nil {
+			return "", err
+		}
+		return info.Timestamp, nil
+	}
+
+	t, err := ParseTime(value, loc, now)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(t.Unix(), 10), nil
+}
+
+// ParseTime resolves an absolute or relative date expression in loc.
+// Accepted: "2024-05-01" and other dateLayouts, "now", "today", "yesterday", "last monday", "monday",
+// "7d" / "12h" / "30m" / "2w" and "3 days ago". Day expressions resolve to the start of the day.
+func ParseTime(value string, loc *time.Location, now time.Time) (time.Time, error) {
+	if loc == nil {
+		loc = DefaultLocation()
+	}
+	now = now.In(loc)
+	value = strings.TrimSpace(value)
+
+	// タイムゾーンの指定がない形式は設定されたタイムゾーンとして解釈
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+
+	expression := strings.Join(strings.Fields(strings.ToLower(value)), " ")
+	today := startOfDay(now)
+	switch expression {
+	case "now", "今":
+		return now, nil
+	case "today", "今日":
+		return today, nil
+	case "yesterday", "昨日":
+		return today.AddDate(0, 0, -1), nil
+	case "一昨日", "おととい":
+		return today.AddDate(0, 0, -2), nil
+	}
+
+	// 曜日: "monday" は今日を含む直近、"last monday" は今日より前の直近
+	if weekday, ok := weekdays[strings.TrimPrefix(expression, "last ")]; ok {
+		days := (int(today.Weekday()) - int(weekday) + 7) % 7
+		if days == 0 && strings.HasPrefix(expression, "last ") {
+			days = 7
+		}
+		return today.AddDate(0, 0, -days), nil
+	}
+
+	// 相対指定: "7d", "3 days ago" など（現在時刻から遡る）
+	matches := relativePattern.FindStringSubmatch(expression)
+	if matches == nil {
+		matches = agoPattern.FindStringSubmatch(expression)
+	}
+	if matches != nil {
+		n, _ := strconv.Atoi(matches[1])
+		switch matches[2] {
+		case "m", "min", "minute":
+			return now.Add(-time.Duration(n) * time.Minute), nil
+		case "h", "hour":
+			return now.Add(-time.Duration(n) * time.Hour), nil
+		case "d", "day":
+			return now.AddDate(0, 0, -n), nil
+		case "w", "week":
+			return now.AddDate(0, 0, -7*n), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf(i18n.T("サポートされていない日時形式です: %s"), value)
+}
+
+// ParseDayRange returns the start and the end (exclusive) of the day given by a date expression
+func ParseDayRange(value string, loc *time.Location, now time.Time) (time.Time, time.Time, error) {
+	t, err := ParseTime(value, loc, now)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	start := startOfDay(t)
+	return start, start.AddDate(0, 0, 1), nil
+}
+
+// ParseWeekRange returns the start (Monday) and the end (exclusive) of an ISO week such as "2024-W18", "this" or "last"
+func ParseWeekRange(value string, loc *time.Location, now time.Time) (time.Time, time.Time, error) {
+	if loc == nil {
+		loc = DefaultLocation()
+	}
+	today := startOfDay(now.In(loc))
+	thisWeek := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+
+	var start time.Time
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "this", "今週":
+		start = thisWeek
+	case "last", "先週":
+		start = thisWeek.AddDate(0, 0, -7)
+	default:
+		matches := isoWeekPattern.FindStringSubmatch(strings.TrimSpace(value))
+		if matches == nil {
+			return time.Time{}, time.Time{}, fmt.Errorf(i18n.T("週の形式が正しくありません: %s（例: 2024-W18, this, last）"), value)
+		}
+		year, _ := strconv.Atoi(matches[1])
+		week, _ := strconv.Atoi(matches[2])
+
+		// 1月4日を含む週がISO週の第1週
+		jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+		start = jan4.AddDate(0, 0, -(int(jan4.Weekday())+6)%7+7*(week-1))
+		if isoYear, isoWeek := start.ISOWeek(); week < 1 || isoYear != year || isoWeek != week {
+			return time.Time{}, time.Time{}, fmt.Errorf(i18n.T("週の形式が正しくありません: %s（例: 2024-W18, this, last）"), value)
+		}
+	}
+	return start, start.AddDate(0, 0, 7), nil
+}
+
+// ParseMonthRange returns the start and the end (exclusive) of a month such as "2024-05", "this" or "last"
+func ParseMonthRange(value string, loc *time.Location, now time.Time) (time.Time, time.Time, error) {
+	if loc == nil {
+		loc = DefaultLocation()
+	}
+	now = now.In(loc)
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+
+	var start time.Time
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "this", "今月":
+		start = thisMonth
+	case "last", "先月":
+		start = thisMonth.AddDate(0, -1, 0)
+	default:
+		t, err := time.ParseInLocation("2006-01", strings.TrimSpace(value), loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf(i18n.T("月の形式が正しくありません: %s（例: 2024-05, this, last）"), value)
+		}
+		start = t
+	}
+	return start, start.AddDate(0, 1, 0), nil
+}
+
+// startOfDay returns midnight of the day of t in t's location
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,157 @@
+package slack
+
+import (
+	"testing"
+	"time"
+)
+
+// testLocation is a non-UTC zone where the local date differs from the UTC date in the evening
+var testLocation = time.FixedZone("EST", -5*60*60)
+
+// testNow is Monday 2024-05-06 22:00 in testLocation (Tuesday in UTC)
+var testNow = time.Date(2024, 5, 7, 3, 0, 0, 0, time.UTC)
+
+func localTime(year int, month time.Month, day, hour, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, testLocation)
+}
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"2024-05-01", localTime(2024, 5, 1, 0, 0), false},
+		{"2024/05/01", localTime(2024, 5, 1, 0, 0), false},
+		{"2024-05-01 10:30", localTime(2024, 5, 1, 10, 30), false},
+		{"2024-05-01T10:00:00+09:00", time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC), false},
+		{"now", localTime(2024, 5, 6, 22, 0), false},
+		{"today", localTime(2024, 5, 6, 0, 0), false},
+		{"yesterday", localTime(2024, 5, 5, 0, 0), false},
+		// 曜日は今日を含む直近、last は今日より前
+		{"monday", localTime(2024, 5, 6, 0, 0), false},
+		{"last monday", localTime(2024, 4, 29, 0, 0), false},
+		{"Last  Sunday", localTime(2024, 5, 5, 0, 0), false},
+		{"fri", localTime(2024, 5, 3, 0, 0), false},
+		// 相対指定は現在時刻から遡る
+		{"7d", localTime(2024, 4, 29, 22, 0), false},
+		{"3 days ago", localTime(2024, 5, 3, 22, 0), false},
+		{"1 week ago", localTime(2024, 4, 29, 22, 0), false},
+		{"2w", localTime(2024, 4, 22, 22, 0), false},
+		{"12h", localTime(2024, 5, 6, 10, 0), false},
+		{"30min", localTime(2024, 5, 6, 21, 30), false},
+		{"45 minutes ago", localTime(2024, 5, 6, 21, 15), false},
+		{"next monday", time.Time{}, true},
+		{"7y", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseTime(tt.value, testLocation, testNow)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTime(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseTime(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseTimeBoundary(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"1714521600.123456", "1714521600.123456", false},
+		{"1714521600", "1714521600", false},
+		{" 1714521600 ", "1714521600", false},
+		{"https://acme.slack.com/archives/C12345678/p1714521600123456", "1714521600.123456", false},
+		{"https://acme.slack.com/archives/C12345678/p1714521600123456?thread_ts=1714521500.000100&cid=C12345678", "1714521600.123456", false},
+		// 2024-05-01 00:00 EST = 05:00 UTC
+		{"2024-05-01", "1714539600", false},
+		{"https://example.com/page", "", true},
+		{"someday", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseTimeBoundary(tt.value, testLocation, testNow)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTimeBoundary(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseTimeBoundary(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseWeekRange(t *testing.T) {
+	// 日曜日 2024-05-12 10:00（週は月曜始まりのため "this" は前の月曜日から）
+	sunday := time.Date(2024, 5, 12, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value   string
+		now     time.Time
+		want    time.Time
+		wantErr bool
+	}{
+		{"2024-W18", testNow, localTime(2024, 4, 29, 0, 0), false},
+		{"2024w1", testNow, localTime(2024, 1, 1, 0, 0), false},
+		{"2020-W53", testNow, localTime(2020, 12, 28, 0, 0), false},
+		{"2021-W53", testNow, time.Time{}, true},
+		{"2024-W00", testNow, time.Time{}, true},
+		{"this", testNow, localTime(2024, 5, 6, 0, 0), false},
+		{"last", testNow, localTime(2024, 4, 29, 0, 0), false},
+		{"this", sunday, localTime(2024, 5, 6, 0, 0), false},
+		{"先週", sunday, localTime(2024, 4, 29, 0, 0), false},
+		{"2024-18", testNow, time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		start, end, err := ParseWeekRange(tt.value, testLocation, tt.now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseWeekRange(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if !start.Equal(tt.want) || !end.Equal(tt.want.AddDate(0, 0, 7)) {
+			t.Errorf("ParseWeekRange(%q) = %v - %v, want %v - %v", tt.value, start, end, tt.want, tt.want.AddDate(0, 0, 7))
+		}
+	}
+}
+
+func TestParseMonthRange(t *testing.T) {
+	// 2024-01-31 22:00 EST（UTC では2月1日）
+	endOfJanuary := time.Date(2024, 2, 1, 3, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value     string
+		now       time.Time
+		wantStart time.Time
+		wantEnd   time.Time
+		wantErr   bool
+	}{
+		{"2024-05", testNow, localTime(2024, 5, 1, 0, 0), localTime(2024, 6, 1, 0, 0), false},
+		{"2023-12", testNow, localTime(2023, 12, 1, 0, 0), localTime(2024, 1, 1, 0, 0), false},
+		{"this", testNow, localTime(2024, 5, 1, 0, 0), localTime(2024, 6, 1, 0, 0), false},
+		{"this", endOfJanuary, localTime(2024, 1, 1, 0, 0), localTime(2024, 2, 1, 0, 0), false},
+		{"last", endOfJanuary, localTime(2023, 12, 1, 0, 0), localTime(2024, 1, 1, 0, 0), false},
+		{"先月", endOfJanuary, localTime(2023, 12, 1, 0, 0), localTime(2024, 1, 1, 0, 0), false},
+		{"2024-13", testNow, time.Time{}, time.Time{}, true},
+		{"may", testNow, time.Time{}, time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		start, end, err := ParseMonthRange(tt.value, testLocation, tt.now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseMonthRange(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+			t.Errorf("ParseMonthRange(%q) at %v = %v - %v, want %v - %v", tt.value, tt.now, start, end, tt.wantStart, tt.wantEnd)
+		}
+	}
+}
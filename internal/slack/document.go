@@ -0,0 +1,399 @@
+package slack
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shellme/slack-tool/internal/i18n"
+	"github.com/slack-go/slack"
+)
+
+// DocumentKind represents what a document contains
+type DocumentKind string
+
+const (
+	// KindThread is a thread (parent message and replies)
+	KindThread DocumentKind = "thread"
+	// KindMessage is a single message
+	KindMessage DocumentKind = "message"
+	// KindChannel is channel history with threads
+	KindChannel DocumentKind = "channel"
+	// KindContext is a message with the messages around it
+	KindContext DocumentKind = "context"
+	// KindBundle is a bundle of documents from several sources
+	KindBundle DocumentKind = "bundle"
+)
+
+// Document is the structured content passed to renderers
+type Document struct {
+	Kind      DocumentKind      `json:"kind"`
+	Title     string            `json:"title"`
+	ChannelID string            `json:"channel_id,omitempty"`
+	Channel   string            `json:"channel,omitempty"`
+	FetchedAt time.Time         `json:"fetched_at"`
+	Part      int               `json:"part,omitempty"`   // 分割した場合の番号（1から）
+	Parts     int               `json:"parts,omitempty"`  // 分割した場合の総数
+	Source    string            `json:"source,omitempty"` // 取得元のURL（バンドルの各セクション）
+	Messages  []DocumentMessage `json:"messages"`
+	Sections  []*Document       `json:"sections,omitempty"` // バンドルの各取得元
+	Users     []DocumentUser    `json:"users,omitempty"`    // バンドルの参加者一覧
+	Stats     *DocumentStats    `json:"stats,omitempty"`    // --stats の統計情報
+}
+
+// DocumentMessage is a message resolved for output (names, decoded text and display times)
+type DocumentMessage struct {
+	Timestamp       string            `json:"ts"`
+	ThreadTimestamp string            `json:"thread_ts,omitempty"`
+	Time            time.Time         `json:"time"`
+	TimeText        string            `json:"-"` // 表示用の日時（--time-format / --author-tz を反映）
+	UserID          string            `json:"user_id,omitempty"`
+	BotID           string            `json:"bot_id,omitempty"`
+	Author          string            `json:"author"`
+	AuthorName      string            `json:"author_name,omitempty"` // 表示名または氏名
+	IsBot           bool              `json:"is_bot,omitempty"`
+	Subtype         string            `json:"subtype,omitempty"`
+	System          bool              `json:"system,omitempty"`
+	Deleted         bool              `json:"deleted,omitempty"`
+	Broadcast       bool              `json:"broadcast,omitempty"`
+	Edited          bool              `json:"edited,omitempty"`
+	EditedAt        *time.Time        `json:"edited_at,omitempty"`
+	EditedText      string            `json:"-"` // 表示用の編集日時（解析できない場合は空）
+	Text            string            `json:"text"`
+	RawText         string            `json:"raw_text,omitempty"` // Slackのマークアップのままの本文
+	Markdown        string            `json:"-"`                  // CommonMarkに変換した本文
+	ReplyCount      int               `json:"reply_count,omitempty"`
+	Reactions       []MessageReaction `json:"reactions,omitempty"`
+	Files           []MessageFile     `json:"files,omitempty"`
+	Permalink       string            `json:"permalink,omitempty"`
+	Replies         []DocumentMessage `json:"replies,omitempty"`
+	Target          bool              `json:"target,omitempty"` // --context で指定したメッセージ
+}
+
+// MessageReaction is a reaction on a message
+type MessageReaction struct {
+	Name  string   `json:"name"`
+	Count int      `json:"count"`
+	Users []string `json:"users,omitempty"`
+}
+
+// MessageFile is a file attached to a message
+type MessageFile struct {
+	Name      string `json:"name"`
+	Title     string `json:"title,omitempty"`
+	Mimetype  string `json:"mimetype,omitempty"`
+	Permalink string `json:"permalink,omitempty"`
+}
+
+// BuildThread builds a document from a thread of messages
+func (f *Formatter) BuildThread(messages []slack.Message, channelID string) (*Document, error) {
+	if len(messages) == 0 {
+		return nil, errors.New(i18n.T("メッセージがありません"))
+	}
+
+	loc := f.timeOptions.Location
+	doc := f.newDocument(KindThread, "Slackスレッドの内容", channelID, "", loc)
+
+	// 親メッセージの下に返信をまとめる（親が除外された場合は返信のみ）
+	parent := -1
+	for _, msg := range dedupeMessages(messages) {
+		if f.shouldSkipMessage(msg) {
+			continue
+		}
+
+		built, err := f.buildMessage(msg, channelID, loc)
+		if err != nil {
+			return nil, fmt.Errorf(i18n.T("メッセージのフォーマットに失敗しました: %v"), err)
+		}
+
+		if parent >= 0 && msg.ThreadTimestamp == doc.Messages[parent].Timestamp {
+			doc.Messages[parent].Replies = append(doc.Messages[parent].Replies, built)
+			continue
+		}
+		doc.Messages = append(doc.Messages, built)
+		if isThreadParentOrStandalone(msg) {
+			parent = len(doc.Messages) - 1
+		}
+	}
+
+	// 匿名化する場合は本文のメールアドレス・URLなどと既知の名前を置き換え
+	if f.anonymizer != nil {
+		f.anonymizer.AnonymizeDocument(doc)
+	}
+
+	return doc, nil
+}
+
+// BuildMessage builds a document from a single message
+func (f *Formatter) BuildMessage(msg slack.Message, channelID string) (*Document, error) {
+	loc := f.timeOptions.Location
+	doc := f.newDocument(KindMessage, "Slackメッセージの内容", channelID, "", loc)
+
+	built, err := f.buildMessage(msg, channelID, loc)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("メッセージのフォーマットに失敗しました: %v"), err)
+	}
+	doc.Messages = []DocumentMessage{built}
+
+	// 匿名化する場合は本文のメールアドレス・URLなどと既知の名前を置き換え
+	if f.anonymizer != nil {
+		f.anonymizer.AnonymizeDocument(doc)
+	}
+
+	return doc, nil
+}
+
+// BuildContext builds a document from a message and the messages around it, marking the message at targetTS
+func (f *Formatter) BuildContext(messages []slack.Message, channelID, targetTS string) (*Document, error) {
+	if len(messages) == 0 {
+		return nil, errors.New(i18n.T("メッセージがありません"))
+	}
+
+	loc := f.timeOptions.Location
+	doc := f.newDocument(KindContext, "Slackメッセージと前後の内容", channelID, "", loc)
+
+	// 返信の前後（スレッド内）でなければ、チャンネルにも投稿された返信はチャンネル側の表示
+	inThread := false
+	for _, msg := range messages {
+		if msg.Timestamp == targetTS {
+			inThread = !isThreadParentOrStandalone(msg)
+		}
+	}
+
+	for _, msg := range dedupeMessages(messages) {
+		if msg.Timestamp != targetTS && (f.shouldSkipMessage(msg) || (!inThread && f.shouldSkipChannelCopy(msg))) {
+			continue
+		}
+
+		built, err := f.buildMessage(msg, channelID, loc)
+		if err != nil {
+			return nil, fmt.Errorf(i18n.T("メッセージのフォーマットに失敗しました: %v"), err)
+		}
+		built.Target = msg.Timestamp == targetTS
+		doc.Messages = append(doc.Messages, built)
+	}
+
+	// 匿名化する場合は本文のメールアドレス・URLなどと既知の名前を置き換え
+	if f.anonymizer != nil {
+		f.anonymizer.AnonymizeDocument(doc)
+	}
+
+	return doc, nil
+}
+
+// BuildChannel builds a document from channel messages, nesting thread replies under their parents
+func (f *Formatter) BuildChannel(messages []slack.Message, channelID, channelName string) (*Document, error) {
+	if len(messages) == 0 {
+		return nil, errors.New(i18n.T("メッセージがありません"))
+	}
+
+	loc := f.timeOptions.Location
+	doc := f.newDocument(KindChannel, "Slackチャンネルの内容", channelID, channelName, loc)
+
+	// スレッドのチャンネルへの同時投稿は履歴と返信の両方に含まれるため重複を除去し、
+	// 新しい順に返される履歴を古い順に並べ替える（分割・削減は先頭を最も古いスレッドとして扱う）
+	messages = f.sortMessagesByTimestamp(dedupeMessages(messages))
+
+	// スレッド返信を親メッセージごとに分離
+	threadReplies := make(map[string][]slack.Message)
+	parents := make(map[string]bool)
+	for _, msg := range messages {
+		if isThreadParentOrStandalone(msg) {
+			parents[msg.Timestamp] = true
+		} else {
+			threadReplies[msg.ThreadTimestamp] = append(threadReplies[msg.ThreadTimestamp], msg)
+		}
+	}
+
+	// 親メッセージが取得範囲外のスレッドは履歴にチャンネル側の表示（同時投稿）のみが含まれる
+	// （親メッセージがフィルタで除外されたスレッドは通常の返信も含む）
+	channelCopiesOnly := make(map[string]bool)
+	for threadTimestamp, replies := range threadReplies {
+		channelCopiesOnly[threadTimestamp] = !parents[threadTimestamp]
+		for _, reply := range replies {
+			if !isThreadBroadcast(reply) {
+				channelCopiesOnly[threadTimestamp] = false
+			}
+		}
+	}
+
+	buildReplies := func(threadTimestamp string) ([]DocumentMessage, error) {
+		var replies []DocumentMessage
+		for _, reply := range f.sortMessagesByTimestamp(threadReplies[threadTimestamp]) {
+			if f.shouldSkipMessage(reply) || (channelCopiesOnly[threadTimestamp] && f.shouldSkipChannelCopy(reply)) {
+				continue
+			}
+			built, err := f.buildMessage(reply, channelID, loc)
+			if err != nil {
+				return nil, fmt.Errorf(i18n.T("スレッド返信のフォーマットに失敗しました: %v"), err)
+			}
+			replies = append(replies, built)
+		}
+		return replies, nil
+	}
+
+	// メインメッセージを時系列で処理
+	orphans := make(map[string]bool)
+	for _, msg := range messages {
+		if !isThreadParentOrStandalone(msg) {
+			// 親メッセージがフィルタで除外された返信は、最初の返信の位置に返信のみ表示
+			if !parents[msg.ThreadTimestamp] && !orphans[msg.ThreadTimestamp] {
+				orphans[msg.ThreadTimestamp] = true
+				replies, err := buildReplies(msg.ThreadTimestamp)
+				if err != nil {
+					return nil, err
+				}
+				doc.Messages = append(doc.Messages, replies...)
+			}
+			continue
+		}
+
+		replies, err := buildReplies(msg.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+
+		// 除外対象の親メッセージは返信のみ表示
+		if f.shouldSkipMessage(msg) {
+			doc.Messages = append(doc.Messages, replies...)
+			continue
+		}
+
+		built, err := f.buildMessage(msg, channelID, loc)
+		if err != nil {
+			return nil, fmt.Errorf(i18n.T("メッセージのフォーマットに失敗しました: %v"), err)
+		}
+		built.Replies = replies
+		doc.Messages = append(doc.Messages, built)
+	}
+
+	// 匿名化する場合は本文のメールアドレス・URLなどと既知の名前を置き換え
+	if f.anonymizer != nil {
+		f.anonymizer.AnonymizeDocument(doc)
+	}
+
+	return doc, nil
+}
+
+// newDocument creates an empty document with a localized title; the channel name is looked up if empty
+func (f *Formatter) newDocument(kind DocumentKind, title, channelID, channelName string, loc *time.Location) *Document {
+	if channelName == "" && channelID != "" {
+		if channel, err := f.getChannelInfo(channelID); err == nil {
+			channelName = channel.Name
+		}
+	}
+
+	return &Document{
+		Kind:      kind,
+		Title:     i18n.T(title),
+		ChannelID: channelID,
+		Channel:   channelName,
+		FetchedAt: time.Now().In(loc),
+	}
+}
+
+// isThreadParentOrStandalone reports whether a message is not a thread reply
+func isThreadParentOrStandalone(msg slack.Message) bool {
+	return msg.ThreadTimestamp == "" || msg.ThreadTimestamp == msg.Timestamp
+}
+
+// buildMessage resolves a message into a DocumentMessage
+func (f *Formatter) buildMessage(msg slack.Message, channelID string, loc *time.Location) (DocumentMessage, error) {
+	// タイムスタンプを表示用タイムゾーンに変換
+	timestamp, err := f.parseTimestamp(msg.Timestamp)
+	if err != nil {
+		return DocumentMessage{}, fmt.Errorf(i18n.T("タイムスタンプの解析に失敗しました: %v"), err)
+	}
+	msgLoc := f.messageLocation(msg, loc)
+
+	built := DocumentMessage{
+		Timestamp:  msg.Timestamp,
+		Time:       timestamp.In(msgLoc),
+		TimeText:   f.formatTime(timestamp, msgLoc),
+		UserID:     msg.User,
+		BotID:      msg.BotID,
+		IsBot:      f.isBotAuthor(msg),
+		Subtype:    msg.SubType,
+		System:     isSystemMessage(msg),
+		Deleted:    isTombstone(msg),
+		Broadcast:  isThreadBroadcast(msg),
+		ReplyCount: msg.ReplyCount,
+		Permalink:  f.client.MessagePermalink(channelID, msg.Timestamp, msg.ThreadTimestamp),
+	}
+	if msg.ThreadTimestamp != "" && msg.ThreadTimestamp != msg.Timestamp {
+		built.ThreadTimestamp = msg.ThreadTimestamp
+	}
+
+	// 削除済みメッセージ（返信が残っているもの）は本文・投稿者を持たない
+	if built.Deleted {
+		built.Author = "-"
+		return built, nil
+	}
+
+	// 投稿者名を取得（ボット・アプリの場合はボット名）
+	built.Author = f.getAuthorName(msg)
+	built.AuthorName = f.getAuthorDisplayName(msg)
+
+	// 本文・ブロック・添付からテキストを組み立ててクリーンアップ
+	body := f.messageBody(msg, loc)
+	built.RawText = msg.Text
+	built.Text = f.cleanMessageText(body)
+	built.Markdown = f.markdownMessageText(body)
+
+	// 匿名化する場合はユーザーIDとマークアップのままの本文を出力しない
+	if f.anonymizer != nil {
+		built.UserID = ""
+		built.RawText = ""
+	}
+
+	if f.subtypeOptions.ShowEdited && msg.Edited != nil && msg.Edited.Timestamp != "" {
+		built.Edited = true
+		if editedTime, err := f.parseTimestamp(msg.Edited.Timestamp); err == nil {
+			editedAt := editedTime.In(msgLoc)
+			built.EditedAt = &editedAt
+			built.EditedText = f.formatTime(editedTime, msgLoc)
+		}
+	}
+
+	for _, reaction := range msg.Reactions {
+		users := reaction.Users
+		if f.anonymizer != nil {
+			users = make([]string, len(reaction.Users))
+			for i, userID := range reaction.Users {
+				// 本文中の名前も置き換えられるよう、ユーザー情報が取得できれば名前とともに登録
+				if user, err := f.getUserInfo(userID); err == nil {
+					users[i] = f.anonymizer.User(user)
+				} else {
+					users[i] = f.anonymizer.Pseudonym(userID)
+				}
+			}
+		}
+		built.Reactions = append(built.Reactions, MessageReaction{
+			Name:  reaction.Name,
+			Count: reaction.Count,
+			Users: users,
+		})
+	}
+	for _, file := range msg.Files {
+		built.Files = append(built.Files, MessageFile{
+			Name:      file.Name,
+			Title:     file.Title,
+			Mimetype:  file.Mimetype,
+			Permalink: file.Permalink,
+		})
+	}
+
+	return built, nil
+}
+
+// flattenMessages returns the messages and their replies in display order
+func flattenMessages(messages []DocumentMessage) []DocumentMessage {
+	var flat []DocumentMessage
+	for _, msg := range messages {
+		replies := msg.Replies
+		msg.Replies = nil
+		flat = append(flat, msg)
+		flat = append(flat, flattenMessages(replies)...)
+	}
+	return flat
+}
@@ -0,0 +1,189 @@
+package slack
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+// broadcastMessage builds a thread reply that was also sent to the channel
+func broadcastMessage(timestamp, threadTimestamp, user, text string) slack.Message {
+	msg := testMessage(timestamp, threadTimestamp, user, text)
+	msg.SubType = "thread_broadcast"
+	return msg
+}
+
+// documentTimestamps returns the timestamps of top-level messages, with replies as "parent>reply"
+func documentTimestamps(doc *Document) []string {
+	var result []string
+	for _, msg := range doc.Messages {
+		result = append(result, msg.Timestamp)
+		for _, reply := range msg.Replies {
+			result = append(result, msg.Timestamp+">"+reply.Timestamp)
+		}
+	}
+	return result
+}
+
+func TestBroadcastsAreKeptInThreads(t *testing.T) {
+	thread := []slack.Message{
+		testMessage("100.000001", "100.000001", "U1", "question"),
+		broadcastMessage("100.000002", "100.000001", "U2", "answer, also sent to the channel"),
+		testMessage("100.000003", "100.000001", "U1", "thanks"),
+	}
+
+	for _, include := range []bool{true, false} {
+		f := newTestFormatter(slack.User{ID: "U1", Name: "taro"}, slack.User{ID: "U2", Name: "hanako"})
+		f.subtypeOptions.IncludeBroadcasts = include
+
+		doc, err := f.BuildThread(thread, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{"100.000001", "100.000001>100.000002", "100.000001>100.000003"}
+		if got := documentTimestamps(doc); !reflect.DeepEqual(got, want) {
+			t.Errorf("BuildThread (include broadcasts: %v) = %v, want %v", include, got, want)
+		}
+	}
+}
+
+func TestBuildChannelBroadcastCopies(t *testing.T) {
+	// 履歴（同時投稿を含む）とスレッドの返信（同じ同時投稿を含む）
+	messages := []slack.Message{
+		testMessage("100.000001", "100.000001", "U1", "question"),
+		broadcastMessage("100.000002", "100.000001", "U2", "answer"),
+		broadcastMessage("100.000002", "100.000001", "U2", "answer"),
+		// 親メッセージが取得範囲外のスレッドの同時投稿（チャンネル側の表示のみ）
+		broadcastMessage("50.000002", "50.000001", "U2", "late answer to an old thread"),
+		testMessage("200.000001", "", "U1", "hello"),
+	}
+
+	tests := []struct {
+		include bool
+		want    []string
+	}{
+		{true, []string{"50.000002", "100.000001", "100.000001>100.000002", "200.000001"}},
+		{false, []string{"100.000001", "100.000001>100.000002", "200.000001"}},
+	}
+
+	for _, tt := range tests {
+		f := newTestFormatter(slack.User{ID: "U1", Name: "taro"}, slack.User{ID: "U2", Name: "hanako"})
+		f.subtypeOptions.IncludeBroadcasts = tt.include
+
+		doc, err := f.BuildChannel(messages, "C1", "general")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := documentTimestamps(doc); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("BuildChannel (include broadcasts: %v) = %v, want %v", tt.include, got, tt.want)
+		}
+	}
+}
+
+func TestBuildChannelBroadcastPlacement(t *testing.T) {
+	withParent := []slack.Message{
+		testMessage("100.000001", "100.000001", "U1", "question"),
+		broadcastMessage("100.000002", "100.000001", "U2", "answer"),
+		testMessage("100.000003", "100.000001", "U1", "thanks"),
+		broadcastMessage("100.000002", "100.000001", "U2", "answer"),
+	}
+	withoutParent := []slack.Message{
+		broadcastMessage("50.000002", "50.000001", "U2", "late answer to an old thread"),
+		testMessage("200.000001", "", "U1", "hello"),
+	}
+
+	tests := []struct {
+		name     string
+		messages []slack.Message
+		include  bool
+		want     []string
+	}{
+		// 親がある場合は設定によらずスレッド内の位置に1回だけ表示する
+		{"parent shown, include", withParent, true, []string{"100.000001", "100.000001>100.000002", "100.000001>100.000003"}},
+		{"parent shown, exclude", withParent, false, []string{"100.000001", "100.000001>100.000002", "100.000001>100.000003"}},
+		// 親がない場合はチャンネル側の表示を IncludeBroadcasts で切り替える
+		{"parent missing, include", withoutParent, true, []string{"50.000002", "200.000001"}},
+		{"parent missing, exclude", withoutParent, false, []string{"200.000001"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestFormatter(slack.User{ID: "U1", Name: "taro"}, slack.User{ID: "U2", Name: "hanako"})
+			f.subtypeOptions.IncludeBroadcasts = tt.include
+
+			doc, err := f.BuildChannel(tt.messages, "C1", "general")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := documentTimestamps(doc); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("BuildChannel = %v, want %v", got, tt.want)
+			}
+			for _, msg := range doc.Messages {
+				for _, reply := range msg.Replies {
+					if want := reply.Timestamp == "100.000002"; reply.Broadcast != want {
+						t.Errorf("reply %s Broadcast = %v, want %v", reply.Timestamp, reply.Broadcast, want)
+					}
+				}
+				if msg.Timestamp == "50.000002" && !msg.Broadcast {
+					t.Errorf("orphaned broadcast %s is not marked as a broadcast", msg.Timestamp)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildChannelSortsOldestFirst(t *testing.T) {
+	// conversations.history は新しい順に返し、スレッドの返信は親の直後に追加される
+	messages := []slack.Message{
+		testMessage("1000000300.000001", "", "U1", "latest"),
+		testMessage("1000000200.000001", "1000000200.000001", "U1", "thread"),
+		testMessage("1000000200.000002", "1000000200.000001", "U2", "reply 1"),
+		testMessage("1000000250.000001", "1000000200.000001", "U2", "reply 2"),
+		testMessage("1000000100.000001", "", "U2", "earlier"),
+		testMessage("999999999.000001", "", "U1", "earliest"),
+	}
+
+	f := newTestFormatter(slack.User{ID: "U1", Name: "taro"}, slack.User{ID: "U2", Name: "hanako"})
+	doc, err := f.BuildChannel(messages, "C1", "general")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"999999999.000001",
+		"1000000100.000001",
+		"1000000200.000001",
+		"1000000200.000001>1000000200.000002",
+		"1000000200.000001>1000000250.000001",
+		"1000000300.000001",
+	}
+	if got := documentTimestamps(doc); !reflect.DeepEqual(got, want) {
+		t.Errorf("BuildChannel() = %v, want %v", got, want)
+	}
+}
+
+func TestBuildContextMarksTarget(t *testing.T) {
+	f := newTestFormatter(slack.User{ID: "U1", Name: "taro"}, slack.User{ID: "U2", Name: "hanako"})
+	messages := []slack.Message{
+		testMessage("100.000001", "", "U1", "before"),
+		testMessage("200.000001", "", "U2", "target"),
+		testMessage("300.000001", "", "U1", "after"),
+	}
+
+	doc, err := f.BuildContext(messages, "C1", "200.000001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var targets []string
+	for _, msg := range doc.Messages {
+		if msg.Target {
+			targets = append(targets, msg.Timestamp)
+		}
+	}
+	if want := []string{"200.000001"}; !reflect.DeepEqual(targets, want) {
+		t.Errorf("targets = %v, want %v", targets, want)
+	}
+	if got, want := documentTimestamps(doc), timestamps(messages); !reflect.DeepEqual(got, want) {
+		t.Errorf("BuildContext = %v, want %v", got, want)
+	}
+}
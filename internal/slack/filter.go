@@ -0,0 +1,204 @@
+package slack
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+// MessageFilter selects messages before they are formatted.
+// Author, text, file and reaction conditions apply to each message (parents and replies alike);
+// reply count and thread conditions apply to whole threads.
+type MessageFilter struct {
+	From         []string       // 投稿者（ユーザーID・@ハンドル・表示名・氏名・ボット名）のいずれか
+	ExcludeUsers []string       // 除外する投稿者（KeepThreads の場合も常にメッセージ単位で除外）
+	Grep         *regexp.Regexp // 本文（デコード後）に一致するメッセージ
+	MinReplies   int            // 返信数がN件以上のスレッド
+	HasFiles     bool           // ファイルが添付されたメッセージ
+	HasReactions []string       // いずれかのリアクションが付いたメッセージ（コロンなしの絵文字名）
+	ThreadsOnly  bool           // 返信のあるスレッドのみ
+	NoThreads    bool           // スレッド返信を除外（トップレベルのメッセージのみ）
+	KeepThreads  bool           // スレッド内のいずれかのメッセージが一致したらスレッド全体を残す
+}
+
+// IsEmpty reports whether the filter has no conditions
+func (mf MessageFilter) IsEmpty() bool {
+	return len(mf.From) == 0 && len(mf.ExcludeUsers) == 0 && mf.Grep == nil && mf.MinReplies == 0 &&
+		!mf.HasFiles && len(mf.HasReactions) == 0 && !mf.ThreadsOnly && !mf.NoThreads
+}
+
+// hasMessageConditions reports whether the filter has conditions evaluated per message
+func (mf MessageFilter) hasMessageConditions() bool {
+	return len(mf.From) > 0 || mf.Grep != nil || mf.HasFiles || len(mf.HasReactions) > 0
+}
+
+// FilterMessages returns the messages that match the filter, keeping their order.
+// Messages are grouped into threads by thread_ts; a reply whose parent is removed is kept on its own.
+func (f *Formatter) FilterMessages(messages []slack.Message, filter MessageFilter) []slack.Message {
+	if filter.IsEmpty() {
+		return messages
+	}
+
+	// 本文の照合で匿名化の仮名が割り当てられないよう、フィルタ中は匿名化しない
+	anonymizer := f.anonymizer
+	f.anonymizer = nil
+	defer func() { f.anonymizer = anonymizer }()
+
+	// スレッドごとの返信数（取得した返信の数と親メッセージの reply_count の大きい方）
+	replyCounts := make(map[string]int)
+	for _, msg := range messages {
+		key := threadKey(msg)
+		if isThreadParentOrStandalone(msg) {
+			replyCounts[key] = max(replyCounts[key], msg.ReplyCount)
+		}
+	}
+	fetchedReplies := make(map[string]int)
+	for _, msg := range messages {
+		if !isThreadParentOrStandalone(msg) {
+			fetchedReplies[threadKey(msg)]++
+		}
+	}
+	for key, count := range fetchedReplies {
+		replyCounts[key] = max(replyCounts[key], count)
+	}
+
+	// メッセージ単位の条件を評価（スレッド全体を残す場合は一致したスレッドを記録）
+	matched := make([]bool, len(messages))
+	matchedThreads := make(map[string]bool)
+	for i, msg := range messages {
+		matched[i] = !filter.hasMessageConditions() || f.matchesMessage(msg, filter)
+		if matched[i] {
+			matchedThreads[threadKey(msg)] = true
+		}
+	}
+
+	var filtered []slack.Message
+	for i, msg := range messages {
+		key := threadKey(msg)
+		switch {
+		case filter.NoThreads && !isThreadParentOrStandalone(msg):
+			continue
+		case filter.ThreadsOnly && replyCounts[key] == 0:
+			continue
+		case replyCounts[key] < filter.MinReplies:
+			continue
+		case f.matchesUser(msg, filter.ExcludeUsers):
+			continue
+		case !matched[i] && !(filter.KeepThreads && matchedThreads[key]):
+			continue
+		}
+		filtered = append(filtered, msg)
+	}
+	return filtered
+}
+
+// FilterBotMessages keeps only messages posted by people (excludeBots) or only those posted by bots and apps (onlyBots).
+// Threads are kept intact: a parent that does not match is kept when any of its replies is kept,
+// so replies are never shown without their parent.
+func (f *Formatter) FilterBotMessages(messages []slack.Message, excludeBots, onlyBots bool) []slack.Message {
+	if !excludeBots && !onlyBots {
+		return messages
+	}
+
+	kept := make([]bool, len(messages))
+	keptReplies := make(map[string]bool)
+	for i, msg := range messages {
+		kept[i] = f.isBotAuthor(msg) == onlyBots
+		if kept[i] && !isThreadParentOrStandalone(msg) {
+			keptReplies[threadKey(msg)] = true
+		}
+	}
+
+	var filtered []slack.Message
+	for i, msg := range messages {
+		// 残した返信の親メッセージは投稿者に関わらず残す
+		if kept[i] || (isThreadParentOrStandalone(msg) && keptReplies[threadKey(msg)]) {
+			filtered = append(filtered, msg)
+		}
+	}
+	return filtered
+}
+
+// isBotAuthor reports whether a message was posted by a bot or an app, including bot users
+// whose messages look like a person's (the user profile has is_bot set)
+func (f *Formatter) isBotAuthor(msg slack.Message) bool {
+	if IsBotMessage(msg) {
+		return true
+	}
+	if msg.User == "" {
+		return false
+	}
+	user, err := f.getUserInfo(msg.User)
+	return err == nil && user.IsBot
+}
+
+// matchesMessage reports whether a message matches the per-message conditions
+func (f *Formatter) matchesMessage(msg slack.Message, filter MessageFilter) bool {
+	if len(filter.From) > 0 && !f.matchesUser(msg, filter.From) {
+		return false
+	}
+	if filter.HasFiles && len(msg.Files) == 0 {
+		return false
+	}
+	if len(filter.HasReactions) > 0 && !hasAnyReaction(msg, filter.HasReactions) {
+		return false
+	}
+	if filter.Grep != nil {
+		text := f.cleanMessageText(f.messageBody(msg, f.timeOptions.Location))
+		if !filter.Grep.MatchString(text) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesUser reports whether the author of a message is one of users
+// (compared with the user ID, bot ID, @handle, display name, real name and bot name, ignoring case)
+func (f *Formatter) matchesUser(msg slack.Message, users []string) bool {
+	if len(users) == 0 || isTombstone(msg) {
+		return false
+	}
+
+	candidates := []string{msg.User, msg.BotID}
+	if IsBotMessage(msg) {
+		candidates = append(candidates, f.getBotName(msg))
+	}
+	if msg.User != "" {
+		if user, err := f.getUserInfo(msg.User); err == nil {
+			candidates = append(candidates, user.Name, user.RealName, user.Profile.DisplayName)
+		}
+	}
+
+	for _, value := range users {
+		value = strings.TrimPrefix(strings.TrimSpace(value), "@")
+		for _, candidate := range candidates {
+			if candidate != "" && strings.EqualFold(candidate, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasAnyReaction reports whether a message has one of the reactions; skin tone variants match the base emoji
+func hasAnyReaction(msg slack.Message, names []string) bool {
+	for _, reaction := range msg.Reactions {
+		base, _, _ := strings.Cut(reaction.Name, "::")
+		for _, name := range names {
+			name = strings.Trim(strings.TrimSpace(name), ":")
+			if reaction.Name == name || base == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// threadKey returns the timestamp identifying the thread of a message (its own timestamp outside threads)
+func threadKey(msg slack.Message) string {
+	if msg.ThreadTimestamp != "" {
+		return msg.ThreadTimestamp
+	}
+	return msg.Timestamp
+}
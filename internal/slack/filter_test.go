@@ -0,0 +1,169 @@
+package slack
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+// newTestFormatter returns a formatter whose user cache holds users, so that no API call is made
+func newTestFormatter(users ...slack.User) *Formatter {
+	f := NewFormatter(NewClient(""))
+	for i := range users {
+		f.users[users[i].ID] = &users[i]
+	}
+	return f
+}
+
+// testMessage builds a message posted by user (a "B" prefix makes it a bot_message)
+func testMessage(timestamp, threadTimestamp, user, text string) slack.Message {
+	msg := slack.Message{}
+	msg.Timestamp = timestamp
+	msg.ThreadTimestamp = threadTimestamp
+	msg.Text = text
+	if user[0] == 'B' {
+		msg.BotID = user
+		msg.SubType = "bot_message"
+	} else {
+		msg.User = user
+	}
+	return msg
+}
+
+func timestamps(messages []slack.Message) []string {
+	var result []string
+	for _, msg := range messages {
+		result = append(result, msg.Timestamp)
+	}
+	return result
+}
+
+func TestFilterBotMessages(t *testing.T) {
+	f := newTestFormatter(
+		slack.User{ID: "U1", Name: "taro"},
+		slack.User{ID: "U2", Name: "hanako"},
+		slack.User{ID: "U9", Name: "deploy-bot", IsBot: true},
+	)
+
+	messages := []slack.Message{
+		// ボットの親メッセージに人の返信
+		testMessage("100.000001", "100.000001", "B1", "alert"),
+		testMessage("100.000002", "100.000001", "U1", "looking"),
+		// 人の親メッセージにボットの返信
+		testMessage("200.000001", "200.000001", "U2", "deploy please"),
+		testMessage("200.000002", "200.000001", "B1", "deployed"),
+		// ボットユーザー（プロフィールの is_bot）の投稿
+		testMessage("300.000001", "", "U9", "nightly build ok"),
+		// 人のみのスレッド
+		testMessage("400.000001", "400.000001", "U1", "question"),
+		testMessage("400.000002", "400.000001", "U2", "answer"),
+		// ボットのみ
+		testMessage("500.000001", "", "B1", "reminder"),
+	}
+
+	tests := []struct {
+		name        string
+		excludeBots bool
+		onlyBots    bool
+		want        []string
+	}{
+		{"no filter", false, false, timestamps(messages)},
+		{
+			"exclude bots keeps bot parents of human replies",
+			true, false,
+			[]string{"100.000001", "100.000002", "200.000001", "400.000001", "400.000002"},
+		},
+		{
+			"only bots keeps human parents of bot replies",
+			false, true,
+			[]string{"100.000001", "200.000001", "200.000002", "300.000001", "500.000001"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := timestamps(f.FilterBotMessages(messages, tt.excludeBots, tt.onlyBots))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FilterBotMessages() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterMessages(t *testing.T) {
+	f := newTestFormatter(
+		slack.User{ID: "U1", Name: "taro", RealName: "Taro Yamada"},
+		slack.User{ID: "U2", Name: "hanako", Profile: slack.UserProfile{DisplayName: "Hana"}},
+		slack.User{ID: "U3", Name: "jiro"},
+	)
+
+	parent := testMessage("100.000001", "100.000001", "U1", "deploy question")
+	parent.ReplyCount = 3 // 取得した返信は1件のみ
+	parent.Reactions = []slack.ItemReaction{{Name: "white_check_mark::skin-tone-2", Count: 1, Users: []string{"U2"}}}
+	bot := testMessage("200.000001", "", "B1", "done")
+	bot.Username = "Deploy"
+
+	messages := []slack.Message{
+		parent,
+		testMessage("100.000002", "100.000001", "U2", "<@U1> &amp; <https://example.com|docs>"),
+		bot,
+		testMessage("300.000001", "300.000001", "U2", "thread"),
+		testMessage("300.000002", "300.000001", "U1", "first"),
+		testMessage("300.000003", "300.000001", "U3", "second"),
+		// 親メッセージが取得範囲外の返信
+		testMessage("400.000001", "350.000001", "U2", "late reply"),
+		testMessage("500.000001", "", "U1", "plain"),
+	}
+
+	tests := []struct {
+		name   string
+		filter MessageFilter
+		want   []string
+	}{
+		{"no filter", MessageFilter{}, timestamps(messages)},
+		{"from handle", MessageFilter{From: []string{"@taro"}}, []string{"100.000001", "300.000002", "500.000001"}},
+		{"from real name", MessageFilter{From: []string{"taro yamada"}}, []string{"100.000001", "300.000002", "500.000001"}},
+		{"from display name", MessageFilter{From: []string{"hana"}}, []string{"100.000002", "300.000001", "400.000001"}},
+		{"from bot name", MessageFilter{From: []string{"deploy"}}, []string{"200.000001"}},
+		{"grep decoded text", MessageFilter{Grep: regexp.MustCompile(`^@taro & docs`)}, []string{"100.000002"}},
+		{"grep raw markup", MessageFilter{Grep: regexp.MustCompile(`<@U1>|&amp;`)}, nil},
+		{"reaction with skin tone", MessageFilter{HasReactions: []string{"white_check_mark"}}, []string{"100.000001"}},
+		{"reaction with exact skin tone", MessageFilter{HasReactions: []string{":white_check_mark::skin-tone-2:"}}, []string{"100.000001"}},
+		{"reaction not found", MessageFilter{HasReactions: []string{"+1"}}, nil},
+		{
+			"threads only",
+			MessageFilter{ThreadsOnly: true},
+			[]string{"100.000001", "100.000002", "300.000001", "300.000002", "300.000003", "400.000001"},
+		},
+		{"no threads", MessageFilter{NoThreads: true}, []string{"100.000001", "200.000001", "300.000001", "500.000001"}},
+		{"keep thread", MessageFilter{From: []string{"jiro"}, KeepThreads: true}, []string{"300.000001", "300.000002", "300.000003"}},
+		{"min replies uses reply_count", MessageFilter{MinReplies: 3}, []string{"100.000001", "100.000002"}},
+		{
+			"min replies uses fetched replies",
+			MessageFilter{MinReplies: 2},
+			[]string{"100.000001", "100.000002", "300.000001", "300.000002", "300.000003"},
+		},
+		{
+			"exclude user wins over keep thread",
+			MessageFilter{From: []string{"jiro"}, KeepThreads: true, ExcludeUsers: []string{"taro"}},
+			[]string{"300.000001", "300.000003"},
+		},
+		{"orphaned reply is kept", MessageFilter{Grep: regexp.MustCompile(`late`)}, []string{"400.000001"}},
+		{
+			"orphaned reply counts as a thread",
+			MessageFilter{MinReplies: 1},
+			[]string{"100.000001", "100.000002", "300.000001", "300.000002", "300.000003", "400.000001"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := timestamps(f.FilterMessages(messages, tt.filter))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FilterMessages() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
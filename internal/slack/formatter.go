@@ -2,11 +2,12 @@ package slack
 
 import (
 	"fmt"
-	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/shellme/slack-tool/internal/i18n"
 	"github.com/slack-go/slack"
 )
 
@@ -15,6 +16,41 @@ type Formatter struct {
 	client     *Client
 	users      map[string]*slack.User      // ユーザー情報のキャッシュ
 	usergroups map[string]*slack.UserGroup // サブチーム情報のキャッシュ
+	channels   map[string]*slack.Channel   // チャンネル情報のキャッシュ
+	bots       map[string]*slack.Bot       // ボット情報のキャッシュ
+
+	subtypeOptions SubtypeOptions            // メッセージ種別ごとの表示設定
+	timeOptions    TimeOptions               // タイムゾーン・日時形式の設定
+	userLocations  map[string]*time.Location // 投稿者ごとのタイムゾーンのキャッシュ
+	anonymizer     *Anonymizer               // 匿名化（nil の場合は実名のまま）
+}
+
+// DefaultTimeFormat is the default layout of message timestamps
+const DefaultTimeFormat = "2006-01-02 15:04:05"
+
+// TimeOptions controls the time zone and layout of timestamps in formatted output
+type TimeOptions struct {
+	Location          *time.Location // 表示に使用するタイムゾーン
+	Format            string         // 日時の表示形式（Goのレイアウト）
+	UseAuthorTimezone bool           // 投稿者ごとのタイムゾーン（user.TZ）で表示する
+}
+
+// DefaultTimeOptions returns the default time options (Asia/Tokyo)
+func DefaultTimeOptions() TimeOptions {
+	return TimeOptions{
+		Location: DefaultLocation(),
+		Format:   DefaultTimeFormat,
+	}
+}
+
+// DefaultLocation returns the default time zone (Asia/Tokyo)
+func DefaultLocation() *time.Location {
+	loc, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		// タイムゾーンデータベースがない環境ではUTC+9の固定タイムゾーンを使用
+		return time.FixedZone("JST", 9*60*60)
+	}
+	return loc
 }
 
 // NewFormatter creates a new formatter
@@ -23,211 +59,174 @@ func NewFormatter(client *Client) *Formatter {
 		client:     client,
 		users:      make(map[string]*slack.User),
 		usergroups: make(map[string]*slack.UserGroup),
+		channels:   make(map[string]*slack.Channel),
+		bots:       make(map[string]*slack.Bot),
+
+		subtypeOptions: DefaultSubtypeOptions(),
+		timeOptions:    DefaultTimeOptions(),
+		userLocations:  make(map[string]*time.Location),
 	}
 }
 
-// FormatThread formats a thread of messages for output
-func (f *Formatter) FormatThread(messages []slack.Message) (string, error) {
-	if len(messages) == 0 {
-		return "", fmt.Errorf("メッセージがありません")
+// SetTimeOptions sets the time zone and layout of timestamps
+func (f *Formatter) SetTimeOptions(opts TimeOptions) {
+	if opts.Location == nil {
+		opts.Location = DefaultLocation()
 	}
-
-	// JSTタイムゾーンを設定
-	jst, err := time.LoadLocation("Asia/Tokyo")
-	if err != nil {
-		return "", fmt.Errorf("タイムゾーンの設定に失敗しました: %v", err)
+	if opts.Format == "" {
+		opts.Format = DefaultTimeFormat
 	}
+	f.timeOptions = opts
+}
 
-	var result strings.Builder
+// SetSubtypeOptions sets how system events, edits, broadcasts and deleted messages are handled
+func (f *Formatter) SetSubtypeOptions(opts SubtypeOptions) {
+	f.subtypeOptions = opts
+}
 
-	// ヘッダーを追加
-	result.WriteString("--- Slackスレッドの内容 (")
-	result.WriteString(time.Now().In(jst).Format("2006/01/02 取得"))
-	result.WriteString(") ---\n\n")
+// SetAnonymizer replaces users with pseudonyms and masks contact details in built documents (nil disables it)
+func (f *Formatter) SetAnonymizer(a *Anonymizer) {
+	f.anonymizer = a
+}
 
-	// 各メッセージをフォーマット
-	for _, msg := range messages {
-		formatted, err := f.formatMessage(msg, jst)
-		if err != nil {
-			return "", fmt.Errorf("メッセージのフォーマットに失敗しました: %v", err)
-		}
+// sortMessagesByTimestamp sorts messages by timestamp, oldest first
+func (f *Formatter) sortMessagesByTimestamp(messages []slack.Message) []slack.Message {
+	sort.SliceStable(messages, func(i, j int) bool {
+		return timestampLess(messages[i].Timestamp, messages[j].Timestamp)
+	})
+	return messages
+}
 
-		result.WriteString(formatted)
-		result.WriteString("\n\n") // メッセージ間に空行を追加
+// timestampLess reports whether the Slack timestamp a is older than b
+func timestampLess(a, b string) bool {
+	// 整数部の桁数が異なる場合は文字列の比較では順序が逆になることがある
+	aSeconds, _, _ := strings.Cut(a, ".")
+	bSeconds, _, _ := strings.Cut(b, ".")
+	if len(aSeconds) != len(bSeconds) {
+		return len(aSeconds) < len(bSeconds)
 	}
-
-	// フッターを追加
-	result.WriteString("--- ここまで ---")
-
-	return result.String(), nil
+	return a < b
 }
 
-// FormatMessage formats a single message for output
-func (f *Formatter) FormatMessage(msg slack.Message) (string, error) {
-	// JSTタイムゾーンを設定
-	jst, err := time.LoadLocation("Asia/Tokyo")
-	if err != nil {
-		return "", fmt.Errorf("タイムゾーンの設定に失敗しました: %v", err)
+// formatTime formats a time with the configured layout; the zone name is added when author time zones are used
+func (f *Formatter) formatTime(t time.Time, loc *time.Location) string {
+	formatted := t.In(loc).Format(f.timeOptions.Format)
+	if f.timeOptions.UseAuthorTimezone {
+		formatted += " " + t.In(loc).Format("MST")
 	}
+	return formatted
+}
 
-	var result strings.Builder
-
-	// ヘッダーを追加
-	result.WriteString("--- Slackメッセージの内容 (")
-	result.WriteString(time.Now().In(jst).Format("2006/01/02 取得"))
-	result.WriteString(") ---\n\n")
+// messageLocation returns the time zone for a message, using the author's time zone if enabled
+func (f *Formatter) messageLocation(msg slack.Message, loc *time.Location) *time.Location {
+	if !f.timeOptions.UseAuthorTimezone || msg.User == "" || IsBotMessage(msg) {
+		return loc
+	}
 
-	// メッセージをフォーマット
-	formatted, err := f.formatMessage(msg, jst)
-	if err != nil {
-		return "", fmt.Errorf("メッセージのフォーマットに失敗しました: %v", err)
+	// キャッシュをチェック
+	if userLoc, exists := f.userLocations[msg.User]; exists {
+		return userLoc
 	}
 
-	result.WriteString(formatted)
-	result.WriteString("\n\n")
+	userLoc := loc
+	if user, err := f.getUserInfo(msg.User); err == nil && user.TZ != "" {
+		if l, err := time.LoadLocation(user.TZ); err == nil {
+			userLoc = l
+		}
+	}
 
-	// フッターを追加
-	result.WriteString("--- ここまで ---")
+	// キャッシュに保存
+	f.userLocations[msg.User] = userLoc
 
-	return result.String(), nil
+	return userLoc
 }
 
-// FormatChannel formats channel messages for output
-func (f *Formatter) FormatChannel(messages []slack.Message, channelName string) (string, error) {
-	if len(messages) == 0 {
-		return "", fmt.Errorf("メッセージがありません")
+// getAuthorName returns the display name of the message author; bots and apps are marked as such
+func (f *Formatter) getAuthorName(msg slack.Message) string {
+	if IsBotMessage(msg) {
+		return "@" + f.getBotName(msg) + i18n.T(" (アプリ)")
 	}
 
-	// JSTタイムゾーンを設定
-	jst, err := time.LoadLocation("Asia/Tokyo")
+	// ユーザー情報を取得（キャッシュから、またはAPIから）
+	user, err := f.getUserInfo(msg.User)
 	if err != nil {
-		return "", fmt.Errorf("タイムゾーンの設定に失敗しました: %v", err)
+		// ユーザー情報が取得できない場合はユーザーIDをそのまま使用
+		if f.anonymizer != nil {
+			return "@" + f.anonymizer.Pseudonym(msg.User)
+		}
+		return "@" + msg.User
 	}
 
-	var result strings.Builder
+	// ユーザー名を取得（@以降の名前）
+	return f.getUsername(user)
+}
 
-	// ヘッダーを追加
-	result.WriteString("--- Slackチャンネルの内容 (")
-	result.WriteString(time.Now().In(jst).Format("2006/01/02 取得"))
-	result.WriteString(") ---\n")
-	if channelName != "" {
-		result.WriteString(fmt.Sprintf("チャンネル: #%s\n", channelName))
+// getAuthorDisplayName returns the display name or real name of the message author (the bot name for bots)
+func (f *Formatter) getAuthorDisplayName(msg slack.Message) string {
+	if IsBotMessage(msg) {
+		return f.getBotName(msg)
 	}
-	result.WriteString("\n")
 
-	// メッセージをスレッド構造でフォーマット
-	formatted, err := f.formatChannelWithThreads(messages, jst)
-	if err != nil {
-		return "", fmt.Errorf("メッセージのフォーマットに失敗しました: %v", err)
+	// 匿名化する場合は仮名（投稿者名）のみ
+	if f.anonymizer != nil {
+		return ""
 	}
 
-	result.WriteString(formatted)
-
-	// フッターを追加
-	result.WriteString("--- ここまで ---")
-
-	return result.String(), nil
+	user, err := f.getUserInfo(msg.User)
+	if err != nil {
+		return ""
+	}
+	if user.Profile.DisplayName != "" {
+		return user.Profile.DisplayName
+	}
+	return user.RealName
 }
 
-// formatChannelWithThreads formats channel messages with thread structure
-func (f *Formatter) formatChannelWithThreads(messages []slack.Message, jst *time.Location) (string, error) {
-	// メインメッセージとスレッド返信を分離
-	mainMessages := make(map[string]slack.Message)
-	threadReplies := make(map[string][]slack.Message)
-
-	for _, msg := range messages {
-		if msg.ThreadTimestamp == "" || msg.ThreadTimestamp == msg.Timestamp {
-			// メインメッセージ
-			mainMessages[msg.Timestamp] = msg
-		} else {
-			// スレッド返信
-			threadReplies[msg.ThreadTimestamp] = append(threadReplies[msg.ThreadTimestamp], msg)
-		}
+// getBotName resolves a bot name from the bot profile, the username or bots.info
+func (f *Formatter) getBotName(msg slack.Message) string {
+	if msg.BotProfile != nil && msg.BotProfile.Name != "" {
+		return msg.BotProfile.Name
+	}
+	if msg.Username != "" {
+		return msg.Username
 	}
 
-	var result strings.Builder
-
-	// メインメッセージを時系列で処理
-	for _, msg := range messages {
-		if msg.ThreadTimestamp == "" || msg.ThreadTimestamp == msg.Timestamp {
-			// メインメッセージをフォーマット
-			formatted, err := f.formatMessage(msg, jst)
-			if err != nil {
-				return "", fmt.Errorf("メッセージのフォーマットに失敗しました: %v", err)
-			}
-			result.WriteString(formatted)
-			result.WriteString("\n")
-
-			// このメッセージのスレッド返信があるかチェック
-			if replies, exists := threadReplies[msg.Timestamp]; exists {
-				// スレッド返信を時系列でソート
-				sortedReplies := f.sortMessagesByTimestamp(replies)
-
-				for _, reply := range sortedReplies {
-					replyFormatted, err := f.formatMessage(reply, jst)
-					if err != nil {
-						return "", fmt.Errorf("スレッド返信のフォーマットに失敗しました: %v", err)
-					}
-
-					// スレッド返信としてインデントして表示
-					lines := strings.Split(replyFormatted, "\n")
-					for i, line := range lines {
-						if i == 0 {
-							result.WriteString(fmt.Sprintf("  └─ %s\n", line))
-						} else {
-							result.WriteString(fmt.Sprintf("     %s\n", line))
-						}
-					}
-				}
-			}
-
-			result.WriteString("\n") // メッセージ間に空行を追加
+	if msg.BotID != "" {
+		if bot, err := f.getBotInfo(msg.BotID); err == nil && bot.Name != "" {
+			return bot.Name
 		}
+		return msg.BotID
 	}
 
-	return result.String(), nil
-}
-
-// sortMessagesByTimestamp sorts messages by timestamp
-func (f *Formatter) sortMessagesByTimestamp(messages []slack.Message) []slack.Message {
-	// バブルソートでタイムスタンプ順にソート
-	for i := 0; i < len(messages)-1; i++ {
-		for j := 0; j < len(messages)-1-i; j++ {
-			if messages[j].Timestamp > messages[j+1].Timestamp {
-				messages[j], messages[j+1] = messages[j+1], messages[j]
-			}
-		}
+	// ボットのユーザーIDで投稿されている場合
+	if user, err := f.getUserInfo(msg.User); err == nil {
+		return strings.TrimPrefix(f.getUsername(user), "@")
 	}
-	return messages
+	return msg.User
 }
 
-// formatMessage formats a single message
-func (f *Formatter) formatMessage(msg slack.Message, jst *time.Location) (string, error) {
-	// ユーザー情報を取得（キャッシュから、またはAPIから）
-	user, err := f.getUserInfo(msg.User)
-	var username string
-	if err != nil {
-		// ユーザー情報が取得できない場合はユーザーIDをそのまま使用
-		username = "@" + msg.User
-	} else {
-		// ユーザー名を取得（@以降の名前）
-		username = f.getUsername(user)
+// getBotInfo gets bot information, using cache if available
+func (f *Formatter) getBotInfo(botID string) (*slack.Bot, error) {
+	// キャッシュをチェック
+	if bot, exists := f.bots[botID]; exists {
+		return bot, nil
 	}
 
-	// タイムスタンプをJSTに変換
-	timestamp, err := f.parseTimestamp(msg.Timestamp)
+	// APIから取得
+	bot, err := f.client.GetBotInfo(botID)
 	if err != nil {
-		return "", fmt.Errorf("タイムスタンプの解析に失敗しました: %v", err)
+		return nil, err
 	}
 
-	jstTime := timestamp.In(jst)
-	timeStr := jstTime.Format("2006-01-02 15:04:05")
+	// キャッシュに保存
+	f.bots[botID] = bot
 
-	// メッセージテキストをクリーンアップ
-	text := f.cleanMessageText(msg.Text)
+	return bot, nil
+}
 
-	// フォーマット: [YYYY-MM-DD HH:MM:SS][@username]: 本文
-	return fmt.Sprintf("[%s][%s]:\n%s", timeStr, username, text), nil
+// IsBotMessage reports whether a message was posted by a bot or an app
+func IsBotMessage(msg slack.Message) bool {
+	return msg.BotID != "" || msg.SubType == "bot_message" || (msg.User == "" && msg.Username != "")
 }
 
 // getUserInfo gets user information, using cache if available
@@ -278,8 +277,31 @@ func (f *Formatter) getUserGroupInfo(groupID string) (*slack.UserGroup, error) {
 	return nil, nil
 }
 
+// getChannelInfo gets channel information, using cache if available
+func (f *Formatter) getChannelInfo(channelID string) (*slack.Channel, error) {
+	// キャッシュをチェック
+	if channel, exists := f.channels[channelID]; exists {
+		return channel, nil
+	}
+
+	// APIから取得
+	channel, err := f.client.GetChannelInfo(channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	// キャッシュに保存
+	f.channels[channelID] = channel
+
+	return channel, nil
+}
+
 // getUsername extracts the @username from user information
 func (f *Formatter) getUsername(user *slack.User) string {
+	if f.anonymizer != nil {
+		return "@" + f.anonymizer.User(user)
+	}
+
 	// ユーザー名の優先順位: Name > RealName > ID
 	if user.Name != "" {
 		return "@" + user.Name
@@ -295,13 +317,13 @@ func (f *Formatter) parseTimestamp(timestamp string) (time.Time, error) {
 	// Slackのタイムスタンプは "1234567890.123456" 形式
 	parts := strings.Split(timestamp, ".")
 	if len(parts) != 2 {
-		return time.Time{}, fmt.Errorf("無効なタイムスタンプ形式: %s", timestamp)
+		return time.Time{}, fmt.Errorf(i18n.T("無効なタイムスタンプ形式: %s"), timestamp)
 	}
 
 	// 秒部分を解析
 	sec, err := strconv.ParseInt(parts[0], 10, 64)
 	if err != nil {
-		return time.Time{}, fmt.Errorf("タイムスタンプの秒部分の解析に失敗: %v", err)
+		return time.Time{}, fmt.Errorf(i18n.T("タイムスタンプの秒部分の解析に失敗: %v"), err)
 	}
 
 	// マイクロ秒部分を解析（最大6桁）
@@ -317,7 +339,7 @@ func (f *Formatter) parseTimestamp(timestamp string) (time.Time, error) {
 
 	microsec, err := strconv.ParseInt(microsecStr, 10, 64)
 	if err != nil {
-		return time.Time{}, fmt.Errorf("タイムスタンプのマイクロ秒部分の解析に失敗: %v", err)
+		return time.Time{}, fmt.Errorf(i18n.T("タイムスタンプのマイクロ秒部分の解析に失敗: %v"), err)
 	}
 
 	// Unix時間からtime.Timeに変換
@@ -331,88 +353,47 @@ func (f *Formatter) cleanMessageText(text string) string {
 
 	// 空のメッセージの場合は適切なメッセージを表示
 	if cleaned == "" {
-		return "(メッセージの内容がありません)"
+		return i18n.T("(メッセージの内容がありません)")
 	}
 
 	// 改行を正規化
 	cleaned = strings.ReplaceAll(cleaned, "\r\n", "\n")
 	cleaned = strings.ReplaceAll(cleaned, "\r", "\n")
 
-	// メンションを変換
-	cleaned = f.convertMentions(cleaned)
+	// メンション・リンク・HTMLエンティティなどのマークアップをデコード
+	cleaned = f.markupDecoder().Decode(cleaned)
 
 	return cleaned
 }
 
-// convertMentions converts Slack mention IDs to readable usernames
-func (f *Formatter) convertMentions(text string) string {
-	// ユーザーメンションの正規表現: <@U08KTGLLCLU>
-	userMentionRegex := regexp.MustCompile(`<@([UW][A-Z0-9]+)>`)
-
-	// サブチームメンションの正規表現: <!subteam^S025PC88BJ5>
-	subteamMentionRegex := regexp.MustCompile(`<!subteam\^([A-Z0-9]+)>`)
-
-	// チャンネルメンションの正規表現: <#C12345678|channel-name>
-	channelMentionRegex := regexp.MustCompile(`<#([A-Z0-9]+)(\|[^>]+)?>`)
-
-	// ユーザーメンションを変換
-	text = userMentionRegex.ReplaceAllStringFunc(text, func(match string) string {
-		// <@U08KTGLLCLU> から U08KTGLLCLU を抽出
-		userID := userMentionRegex.FindStringSubmatch(match)[1]
-
-		// ユーザー情報を取得
-		user, err := f.getUserInfo(userID)
-		if err != nil {
-			// ユーザー情報が取得できない場合は元のIDを表示
-			return "@" + userID
-		}
-
-		// ユーザー名を取得（@は除く）
-		username := f.getUsername(user)
-		username = strings.TrimPrefix(username, "@")
-
-		return "@" + username
-	})
-
-	// サブチームメンションを変換
-	text = subteamMentionRegex.ReplaceAllStringFunc(text, func(match string) string {
-		// <!subteam^S025PC88BJ5> から S025PC88BJ5 を抽出
-		subteamID := subteamMentionRegex.FindStringSubmatch(match)[1]
-
-		// サブチーム情報を取得
-		group, err := f.getUserGroupInfo(subteamID)
-		if err != nil {
-			// エラーの場合はIDをそのまま表示
-			return "@" + subteamID
-		}
-
-		if group != nil && group.Handle != "" {
-			// ハンドル名が取得できた場合
-			return "@" + group.Handle
-		}
-
-		// ハンドル名が取得できない場合はIDをそのまま表示
-		return "@" + subteamID
-	})
-
-	// チャンネルメンションを変換
-	text = channelMentionRegex.ReplaceAllStringFunc(text, func(match string) string {
-		// <#C12345678|channel-name> から C12345678 と channel-name を抽出
-		matches := channelMentionRegex.FindStringSubmatch(match)
-		channelID := matches[1]
-		channelName := ""
-
-		// チャンネル名が含まれている場合
-		if len(matches) > 2 && matches[2] != "" {
-			channelName = matches[2][1:] // |を除去
-		}
-
-		if channelName != "" {
-			return "#" + channelName
-		}
-
-		return "#" + channelID
-	})
-
-	return text
+// markupDecoder creates a markup decoder that resolves IDs through the formatter's caches
+func (f *Formatter) markupDecoder() *MarkupDecoder {
+	return &MarkupDecoder{
+		Location: f.timeOptions.Location,
+		ResolveUser: func(userID string) string {
+			user, err := f.getUserInfo(userID)
+			if err != nil {
+				// ユーザー情報が取得できない場合は元のIDを表示
+				if f.anonymizer != nil {
+					return f.anonymizer.Pseudonym(userID)
+				}
+				return ""
+			}
+			return strings.TrimPrefix(f.getUsername(user), "@")
+		},
+		ResolveUserGroup: func(groupID string) string {
+			group, err := f.getUserGroupInfo(groupID)
+			if err != nil || group == nil {
+				return ""
+			}
+			return group.Handle
+		},
+		ResolveChannel: func(channelID string) string {
+			channel, err := f.getChannelInfo(channelID)
+			if err != nil {
+				return ""
+			}
+			return channel.Name
+		},
+	}
 }
@@ -0,0 +1,757 @@
+package slack
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/slack-go/slack"
+)
+
+// MarkdownMessage is a message converted from CommonMark for posting to Slack
+type MarkdownMessage struct {
+	// Text is the mrkdwn text (also used as the notification fallback when Blocks is set)
+	Text string `json:"text"`
+	// Blocks holds rich_text blocks for content that mrkdwn cannot express (nil if not needed)
+	Blocks []slack.Block `json:"blocks,omitempty"`
+}
+
+// MsgOptions returns the options for posting the message
+func (m *MarkdownMessage) MsgOptions() []slack.MsgOption {
+	if len(m.Blocks) == 0 {
+		return nil
+	}
+	return []slack.MsgOption{slack.MsgOptionBlocks(m.Blocks...)}
+}
+
+// ConvertMarkdown converts CommonMark (with GitHub tables and strikethrough) to a Slack message.
+// The text is always converted to mrkdwn; rich_text blocks are added when the document contains
+// lists, tables or styles that mrkdwn cannot express.
+func ConvertMarkdown(markdown string) *MarkdownMessage {
+	blocks := parseMarkdownBlocks(splitMarkdownLines(markdown))
+
+	message := &MarkdownMessage{Text: markdownBlocksToMrkdwn(blocks)}
+	if markdownNeedsRichText(blocks) {
+		message.Blocks = markdownBlocksToRichText(blocks)
+	}
+	return message
+}
+
+// markdownBlockKind is the kind of a block-level Markdown element
+type markdownBlockKind int
+
+const (
+	markdownParagraph markdownBlockKind = iota
+	markdownHeading
+	markdownCode
+	markdownList
+	markdownQuote
+	markdownTable
+	markdownDivider
+)
+
+// markdownBlock is a block-level Markdown element
+type markdownBlock struct {
+	Kind     markdownBlockKind
+	Level    int               // 見出しのレベル
+	Text     string            // 段落・見出しのインライン記法、またはコードの内容
+	Ordered  bool              // 番号付きリスト
+	Start    int               // 番号付きリストの開始番号
+	Items    [][]markdownBlock // リストの項目
+	Children []markdownBlock   // 引用の内容
+	Rows     [][]string        // 表（1行目は見出し行）
+}
+
+// markdownInline is a run of text with the same style
+type markdownInline struct {
+	Text  string
+	URL   string // リンク先
+	Token string // Slackのマークアップ（@U123 / #C123 / !here など）
+	Style slack.RichTextSectionTextStyle
+}
+
+var (
+	markdownFenceRegex      = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})[ \t]*([^`]*)$")
+	markdownATXHeadingRegex = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	markdownSetextRegex     = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	markdownDividerRegex    = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	markdownListItemRegex   = regexp.MustCompile(`^( {0,3})([-*+]|[0-9]{1,9}[.)])(?:([ \t]+)(.*))?$`)
+	markdownQuoteRegex      = regexp.MustCompile(`^ {0,3}> ?(.*)$`)
+	markdownTableDelimRegex = regexp.MustCompile(`^ {0,3}\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	markdownLinkTargetRegex = regexp.MustCompile(`^\([ \t]*<?([^\s<>()]+)>?(?:[ \t]+(?:"[^"]*"|'[^']*'))?[ \t]*\)`)
+	markdownAutolinkRegex   = regexp.MustCompile(`^<((?:https?://|mailto:)[^<>\s]+|[@#!][^<>\s]+)>`)
+)
+
+// markdownEscapable lists the ASCII punctuation that can be escaped with a backslash
+const markdownEscapable = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+// splitMarkdownLines normalizes line endings and expands leading tabs
+func splitMarkdownLines(markdown string) []string {
+	markdown = strings.ReplaceAll(markdown, "\r\n", "\n")
+	markdown = strings.ReplaceAll(markdown, "\r", "\n")
+
+	lines := strings.Split(markdown, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		indent := line[:len(line)-len(trimmed)]
+		lines[i] = strings.ReplaceAll(indent, "\t", "    ") + trimmed
+	}
+	return lines
+}
+
+// parseMarkdownBlocks parses lines into block-level elements
+func parseMarkdownBlocks(lines []string) []markdownBlock {
+	var blocks []markdownBlock
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			i++
+
+		case markdownFenceRegex.MatchString(line):
+			// フェンス付きコードブロック（閉じるフェンスがなければ最後まで）
+			fence := markdownFenceRegex.FindStringSubmatch(line)[1]
+			var content []string
+			i++
+			for ; i < len(lines); i++ {
+				trimmed := strings.TrimSpace(lines[i])
+				if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+					i++
+					break
+				}
+				content = append(content, lines[i])
+			}
+			blocks = append(blocks, markdownBlock{Kind: markdownCode, Text: strings.Join(content, "\n")})
+
+		case markdownATXHeadingRegex.MatchString(line):
+			match := markdownATXHeadingRegex.FindStringSubmatch(line)
+			blocks = append(blocks, markdownBlock{Kind: markdownHeading, Level: len(match[1]), Text: match[2]})
+			i++
+
+		case markdownDividerRegex.MatchString(line):
+			blocks = append(blocks, markdownBlock{Kind: markdownDivider})
+			i++
+
+		case markdownQuoteRegex.MatchString(line):
+			var content []string
+			for ; i < len(lines) && markdownQuoteRegex.MatchString(lines[i]); i++ {
+				content = append(content, markdownQuoteRegex.FindStringSubmatch(lines[i])[1])
+			}
+			blocks = append(blocks, markdownBlock{Kind: markdownQuote, Children: parseMarkdownBlocks(content)})
+
+		case markdownListItemRegex.MatchString(line):
+			var list markdownBlock
+			list, i = parseMarkdownList(lines, i)
+			blocks = append(blocks, list)
+
+		case isMarkdownTableStart(lines, i):
+			table := markdownBlock{Kind: markdownTable, Rows: [][]string{splitMarkdownTableRow(line)}}
+			for i += 2; i < len(lines) && strings.TrimSpace(lines[i]) != "" && strings.Contains(lines[i], "|"); i++ {
+				table.Rows = append(table.Rows, splitMarkdownTableRow(lines[i]))
+			}
+			blocks = append(blocks, table)
+
+		case strings.HasPrefix(line, "    "):
+			// インデントされたコードブロック
+			var content []string
+			for ; i < len(lines) && (strings.HasPrefix(lines[i], "    ") || strings.TrimSpace(lines[i]) == ""); i++ {
+				content = append(content, strings.TrimPrefix(lines[i], "    "))
+			}
+			blocks = append(blocks, markdownBlock{Kind: markdownCode, Text: strings.TrimRight(strings.Join(content, "\n"), "\n")})
+
+		default:
+			// 段落（空行または他のブロックの開始まで）
+			content := []string{line}
+			for i++; i < len(lines) && !isMarkdownParagraphEnd(lines, i); i++ {
+				content = append(content, lines[i])
+			}
+
+			// 下線付きの見出し（=== / ---）
+			if i < len(lines) && markdownSetextRegex.MatchString(lines[i]) {
+				level := 2
+				if strings.Contains(lines[i], "=") {
+					level = 1
+				}
+				blocks = append(blocks, markdownBlock{Kind: markdownHeading, Level: level, Text: joinMarkdownParagraph(content)})
+				i++
+				continue
+			}
+			blocks = append(blocks, markdownBlock{Kind: markdownParagraph, Text: joinMarkdownParagraph(content)})
+		}
+	}
+
+	return blocks
+}
+
+// isMarkdownParagraphEnd reports whether line i ends the current paragraph
+func isMarkdownParagraphEnd(lines []string, i int) bool {
+	line := lines[i]
+	return strings.TrimSpace(line) == "" ||
+		markdownSetextRegex.MatchString(line) ||
+		markdownFenceRegex.MatchString(line) ||
+		markdownATXHeadingRegex.MatchString(line) ||
+		markdownDividerRegex.MatchString(line) ||
+		markdownQuoteRegex.MatchString(line) ||
+		markdownListItemRegex.MatchString(line) ||
+		isMarkdownTableStart(lines, i)
+}
+
+// isMarkdownTableStart reports whether a table (header row and delimiter row) starts at line i
+func isMarkdownTableStart(lines []string, i int) bool {
+	return i+1 < len(lines) && strings.Contains(lines[i], "|") &&
+		strings.Contains(lines[i+1], "|") && markdownTableDelimRegex.MatchString(lines[i+1])
+}
+
+// splitMarkdownTableRow splits a table row into cells
+func splitMarkdownTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = strings.TrimSuffix(line, "|")
+	}
+
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// parseMarkdownList parses a list starting at line i and returns it with the next line index
+func parseMarkdownList(lines []string, i int) (markdownBlock, int) {
+	first := markdownListItemRegex.FindStringSubmatch(lines[i])
+	list := markdownBlock{Kind: markdownList}
+	marker := first[2]
+	if number, err := strconv.Atoi(marker[:len(marker)-1]); err == nil {
+		list.Ordered = true
+		list.Start = number
+	}
+
+	for i < len(lines) {
+		match := markdownListItemRegex.FindStringSubmatch(lines[i])
+		if match == nil || !sameMarkdownListMarker(match[2], marker) {
+			break
+		}
+
+		// 項目の内容のインデント（マーカーの後の空白が5つ以上ならコードとみなし1つ分）
+		spaces := len(match[3])
+		if spaces == 0 || spaces > 4 {
+			spaces = 1
+		}
+		contentIndent := len(match[1]) + len(match[2]) + spaces
+		content := []string{strings.Repeat(" ", len(match[3])-spaces) + match[4]}
+
+		for i++; i < len(lines); i++ {
+			line := lines[i]
+			switch {
+			case strings.TrimSpace(line) == "":
+				// 次の内容行がインデントされていれば項目の続き
+				next := i + 1
+				for next < len(lines) && strings.TrimSpace(lines[next]) == "" {
+					next++
+				}
+				if next < len(lines) && markdownIndent(lines[next]) >= contentIndent {
+					content = append(content, "")
+					continue
+				}
+			case markdownIndent(line) >= contentIndent:
+				content = append(content, line[contentIndent:])
+				continue
+			case !isMarkdownParagraphEnd(lines, i) && strings.TrimSpace(content[len(content)-1]) != "":
+				// 段落の遅延継続行
+				content = append(content, strings.TrimLeft(line, " "))
+				continue
+			}
+			break
+		}
+		list.Items = append(list.Items, parseMarkdownBlocks(content))
+
+		// 空行を挟んで同じ種類の項目が続く場合は同じリスト
+		next := i
+		for next < len(lines) && strings.TrimSpace(lines[next]) == "" {
+			next++
+		}
+		if next < len(lines) {
+			if match := markdownListItemRegex.FindStringSubmatch(lines[next]); match != nil && sameMarkdownListMarker(match[2], marker) {
+				i = next
+			}
+		}
+	}
+
+	return list, i
+}
+
+// sameMarkdownListMarker reports whether two list markers belong to the same list
+func sameMarkdownListMarker(a, b string) bool {
+	_, errA := strconv.Atoi(a[:len(a)-1])
+	_, errB := strconv.Atoi(b[:len(b)-1])
+	if errA == nil && errB == nil {
+		return a[len(a)-1] == b[len(b)-1]
+	}
+	return a == b
+}
+
+// markdownIndent returns the number of leading spaces
+func markdownIndent(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// joinMarkdownParagraph joins paragraph lines; soft breaks become spaces and hard breaks newlines
+func joinMarkdownParagraph(lines []string) string {
+	var result strings.Builder
+	for i, line := range lines {
+		line = strings.TrimLeft(line, " ")
+		hardBreak := strings.HasSuffix(line, "  ") || strings.HasSuffix(line, `\`)
+		line = strings.TrimRight(line, " ")
+		if strings.HasSuffix(line, `\`) {
+			line = strings.TrimSuffix(line, `\`)
+		}
+		result.WriteString(line)
+
+		if i == len(lines)-1 {
+			break
+		}
+		next := strings.TrimLeft(lines[i+1], " ")
+		switch {
+		case hardBreak:
+			result.WriteString("\n")
+		case !isWideLineBoundary(line, next):
+			result.WriteString(" ")
+		}
+	}
+	return result.String()
+}
+
+// isWideLineBoundary reports whether a soft break joins two CJK characters (which need no space)
+func isWideLineBoundary(before, after string) bool {
+	last, _ := utf8.DecodeLastRuneInString(before)
+	first, _ := utf8.DecodeRuneInString(after)
+	return runeWidth(last) == 2 && runeWidth(first) == 2
+}
+
+// parseMarkdownInline parses inline Markdown into runs with the base style applied
+func parseMarkdownInline(text string, base markdownInline) []markdownInline {
+	var runs []markdownInline
+	var plain strings.Builder
+	flush := func() {
+		if plain.Len() > 0 {
+			run := base
+			run.Text = plain.String()
+			runs = append(runs, run)
+			plain.Reset()
+		}
+	}
+
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == '\\' && i+1 < len(text) && strings.IndexByte(markdownEscapable, text[i+1]) >= 0:
+			// バックスラッシュエスケープ
+			plain.WriteByte(text[i+1])
+			i += 2
+			continue
+
+		case c == '`':
+			// コードスパン（同じ長さのバッククォートで閉じる）
+			fence := text[i : i+len(text[i:])-len(strings.TrimLeft(text[i:], "`"))]
+			if end := strings.Index(text[i+len(fence):], fence); end >= 0 {
+				flush()
+				code := text[i+len(fence) : i+len(fence)+end]
+				if len(code) > 2 && strings.HasPrefix(code, " ") && strings.HasSuffix(code, " ") {
+					code = code[1 : len(code)-1]
+				}
+				run := base
+				run.Text = strings.ReplaceAll(code, "\n", " ")
+				run.Style.Code = true
+				runs = append(runs, run)
+				i += len(fence)*2 + end
+				continue
+			}
+			plain.WriteString(fence)
+			i += len(fence)
+			continue
+
+		case c == '<':
+			// 自動リンクとSlackのマークアップ（<@U123> など）
+			if match := markdownAutolinkRegex.FindStringSubmatch(text[i:]); match != nil {
+				flush()
+				run := base
+				if strings.ContainsAny(match[1][:1], "@#!") {
+					run.Token = match[1]
+					run.Text = match[1]
+				} else {
+					run.URL = match[1]
+					run.Text = strings.TrimPrefix(match[1], "mailto:")
+				}
+				runs = append(runs, run)
+				i += len(match[0])
+				continue
+			}
+
+		case c == '[' || c == '!' && strings.HasPrefix(text[i:], "!["):
+			// リンク [text](url) と画像 ![alt](url)
+			image := c == '!'
+			start := i + 1
+			if image {
+				start++
+			}
+			if end := matchingBracket(text, start-1); end >= 0 {
+				if target := markdownLinkTargetRegex.FindStringSubmatch(text[end+1:]); target != nil {
+					flush()
+					label := text[start:end]
+					link := base
+					link.URL = target[1]
+					if image || strings.TrimSpace(label) == "" {
+						if label == "" {
+							label = target[1]
+						}
+						link.Text = label
+						runs = append(runs, link)
+					} else {
+						runs = append(runs, parseMarkdownInline(label, link)...)
+					}
+					i = end + 1 + len(target[0])
+					continue
+				}
+			}
+
+		case c == '*' || c == '_' || c == '~':
+			// 強調・取り消し線
+			delimiter := text[i : i+len(text[i:])-len(strings.TrimLeft(text[i:], string(c)))]
+			if inner, length, ok := matchMarkdownEmphasis(text, i, delimiter); ok {
+				flush()
+				style := base
+				switch {
+				case c == '~':
+					style.Style.Strike = true
+				case len(delimiter) >= 3:
+					style.Style.Bold, style.Style.Italic = true, true
+				case len(delimiter) == 2:
+					style.Style.Bold = true
+				default:
+					style.Style.Italic = true
+				}
+				runs = append(runs, parseMarkdownInline(inner, style)...)
+				i += length
+				continue
+			}
+			plain.WriteString(delimiter)
+			i += len(delimiter)
+			continue
+		}
+
+		plain.WriteByte(c)
+		i++
+	}
+	flush()
+
+	return mergeMarkdownInlines(runs)
+}
+
+// matchingBracket returns the index of the "]" matching the "[" at open, or -1
+func matchingBracket(text string, open int) int {
+	depth := 0
+	for i := open; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// matchMarkdownEmphasis finds the closing delimiter for an emphasis starting at i.
+// It returns the inner text and the total length including delimiters.
+func matchMarkdownEmphasis(text string, i int, delimiter string) (string, int, bool) {
+	c := delimiter[0]
+	if c == '~' && len(delimiter) != 2 {
+		return "", 0, false
+	}
+	if len(delimiter) > 3 {
+		return "", 0, false
+	}
+
+	// 開始記号の直後が空白なら強調ではない。"_" は単語の途中では強調にならない
+	start := i + len(delimiter)
+	if start >= len(text) || text[start] == ' ' || text[start] == '\n' {
+		return "", 0, false
+	}
+	if c == '_' && i > 0 && isMarkdownWordByte(text[i-1]) {
+		return "", 0, false
+	}
+
+	for j := start; j < len(text); j++ {
+		if text[j] == '`' {
+			// コードスパン内の記号は対象外
+			if end := strings.IndexByte(text[j+1:], '`'); end >= 0 {
+				j += end + 1
+			}
+			continue
+		}
+		if text[j] != c {
+			continue
+		}
+		run := len(text[j:]) - len(strings.TrimLeft(text[j:], string(c)))
+		if run != len(delimiter) || text[j-1] == ' ' || text[j-1] == '\n' {
+			j += run - 1
+			continue
+		}
+		if c == '_' && j+run < len(text) && isMarkdownWordByte(text[j+run]) {
+			j += run - 1
+			continue
+		}
+		return text[start:j], j + run - i, true
+	}
+	return "", 0, false
+}
+
+// isMarkdownWordByte reports whether b is an ASCII letter or digit
+func isMarkdownWordByte(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9'
+}
+
+// mergeMarkdownInlines merges adjacent runs with the same style
+func mergeMarkdownInlines(runs []markdownInline) []markdownInline {
+	var merged []markdownInline
+	for _, run := range runs {
+		if last := len(merged) - 1; last >= 0 && run.Token == "" && merged[last].Token == "" &&
+			merged[last].URL == run.URL && merged[last].Style == run.Style {
+			merged[last].Text += run.Text
+			continue
+		}
+		merged = append(merged, run)
+	}
+	return merged
+}
+
+// markdownNeedsRichText reports whether blocks contain elements that mrkdwn cannot express:
+// lists, tables and styles adjacent to letters (mrkdwn only styles text surrounded by spaces)
+func markdownNeedsRichText(blocks []markdownBlock) bool {
+	for _, block := range blocks {
+		switch block.Kind {
+		case markdownList, markdownTable:
+			return true
+		case markdownParagraph, markdownHeading:
+			if hasAdjacentStyle(parseMarkdownInline(block.Text, markdownInline{})) {
+				return true
+			}
+		case markdownQuote:
+			if markdownNeedsRichText(block.Children) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasAdjacentStyle reports whether a styled run touches a letter or digit of a neighbouring run
+func hasAdjacentStyle(runs []markdownInline) bool {
+	styled := func(run markdownInline) bool {
+		return run.Style != (slack.RichTextSectionTextStyle{}) && run.URL == ""
+	}
+	for i, run := range runs {
+		if !styled(run) {
+			continue
+		}
+		if i > 0 && !styled(runs[i-1]) {
+			if last, _ := utf8.DecodeLastRuneInString(runs[i-1].Text); unicode.IsLetter(last) || unicode.IsDigit(last) {
+				return true
+			}
+		}
+		if i+1 < len(runs) && !styled(runs[i+1]) {
+			if first, _ := utf8.DecodeRuneInString(runs[i+1].Text); unicode.IsLetter(first) || unicode.IsDigit(first) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// markdownBlocksToMrkdwn converts blocks to mrkdwn text
+func markdownBlocksToMrkdwn(blocks []markdownBlock) string {
+	var parts []string
+	for _, block := range blocks {
+		parts = append(parts, markdownBlockToMrkdwn(block))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// markdownBlockToMrkdwn converts a single block to mrkdwn text
+func markdownBlockToMrkdwn(block markdownBlock) string {
+	switch block.Kind {
+	case markdownHeading:
+		// mrkdwn には見出しがないため太字の行にする
+		return markdownInlinesToMrkdwn(parseMarkdownInline(block.Text, markdownInline{Style: slack.RichTextSectionTextStyle{Bold: true}}))
+	case markdownCode:
+		return "```\n" + markupEscaper.Replace(block.Text) + "\n```"
+	case markdownTable:
+		return "```\n" + markupEscaper.Replace(formatMarkdownTable(block.Rows)) + "\n```"
+	case markdownDivider:
+		return "──────────"
+	case markdownQuote:
+		lines := strings.Split(markdownBlocksToMrkdwn(block.Children), "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight("> "+line, " ")
+		}
+		return strings.Join(lines, "\n")
+	case markdownList:
+		return strings.Join(markdownListLines(block, 0, markdownBlockToMrkdwn), "\n")
+	default:
+		return markdownInlinesToMrkdwn(parseMarkdownInline(block.Text, markdownInline{}))
+	}
+}
+
+// markdownBullets are the bullet characters by nesting depth, matching Slack's own lists
+var markdownBullets = []string{"•", "◦", "▪"}
+
+// markdownListLines converts a list to lines with bullet characters or numbers, converting item content with convert
+func markdownListLines(list markdownBlock, depth int, convert func(markdownBlock) string) []string {
+	indent := strings.Repeat("    ", depth)
+
+	var lines []string
+	for n, item := range list.Items {
+		marker := markdownBullets[depth%len(markdownBullets)] + " "
+		if list.Ordered {
+			marker = strconv.Itoa(list.Start+n) + ". "
+		}
+
+		first := true
+		for _, block := range item {
+			if block.Kind == markdownList {
+				lines = append(lines, markdownListLines(block, depth+1, convert)...)
+				continue
+			}
+			for _, line := range strings.Split(convert(block), "\n") {
+				if first {
+					lines = append(lines, indent+marker+line)
+					first = false
+				} else {
+					lines = append(lines, indent+strings.Repeat(" ", utf8.RuneCountInString(marker))+line)
+				}
+			}
+		}
+		if first {
+			lines = append(lines, indent+strings.TrimRight(marker, " "))
+		}
+	}
+	return lines
+}
+
+// markdownInlinesToMrkdwn converts inline runs to mrkdwn
+func markdownInlinesToMrkdwn(runs []markdownInline) string {
+	var result strings.Builder
+	for _, run := range runs {
+		style := run.Style
+		switch {
+		case run.Token != "":
+			result.WriteString("<" + run.Token + ">")
+		case run.URL != "":
+			link := "<" + run.URL + "|" + markupEscaper.Replace(strings.ReplaceAll(run.Text, "|", "｜")) + ">"
+			if run.Text == run.URL {
+				link = "<" + run.URL + ">"
+			}
+			style.Code = false
+			result.WriteString(applyRichTextStyle(link, &style))
+		default:
+			result.WriteString(applyRichTextStyle(markupEscaper.Replace(run.Text), &style))
+		}
+	}
+	return result.String()
+}
+
+// markdownPlainText returns inline Markdown as plain text (link targets are omitted)
+func markdownPlainText(text string) string {
+	var result strings.Builder
+	for _, run := range parseMarkdownInline(text, markdownInline{}) {
+		result.WriteString(run.Text)
+	}
+	return result.String()
+}
+
+// formatMarkdownTable lays out table rows as aligned plain text
+func formatMarkdownTable(rows [][]string) string {
+	var widths []int
+	cells := make([][]string, len(rows))
+	for i, row := range rows {
+		for j, cell := range row {
+			text := markdownPlainText(cell)
+			cells[i] = append(cells[i], text)
+			if j >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if width := displayWidth(text); width > widths[j] {
+				widths[j] = width
+			}
+		}
+	}
+
+	var lines []string
+	for i, row := range cells {
+		var parts []string
+		for j, width := range widths {
+			cell := ""
+			if j < len(row) {
+				cell = row[j]
+			}
+			parts = append(parts, cell+strings.Repeat(" ", width-displayWidth(cell)))
+		}
+		lines = append(lines, strings.TrimRight(strings.Join(parts, " | "), " "))
+
+		// 見出し行の下に区切り線
+		if i == 0 {
+			var rules []string
+			for _, width := range widths {
+				rules = append(rules, strings.Repeat("-", width))
+			}
+			lines = append(lines, strings.Join(rules, "-+-"))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// displayWidth returns the width of text in a monospace font (East Asian wide characters count as 2)
+func displayWidth(text string) int {
+	width := 0
+	for _, r := range text {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// runeWidth returns 2 for East Asian wide and fullwidth characters, otherwise 1
+func runeWidth(r rune) int {
+	switch {
+	case r >= 0x1100 && r <= 0x115F,
+		r >= 0x2E80 && r <= 0xA4CF,
+		r >= 0xAC00 && r <= 0xD7A3,
+		r >= 0xF900 && r <= 0xFAFF,
+		r >= 0xFE30 && r <= 0xFE4F,
+		r >= 0xFF00 && r <= 0xFF60,
+		r >= 0xFFE0 && r <= 0xFFE6,
+		r >= 0x1F300 && r <= 0x1FAFF,
+		r >= 0x20000 && r <= 0x3FFFD:
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,243 @@
+package slack
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/slack-go/slack"
+)
+
+// headerTextLimit is the maximum length of the text in a header block
+const headerTextLimit = 150
+
+// markdownBlocksToRichText converts blocks to Slack blocks.
+// Headings become header blocks, thematic breaks divider blocks, and everything else rich_text blocks.
+func markdownBlocksToRichText(blocks []markdownBlock) []slack.Block {
+	var result []slack.Block
+	var elements []slack.RichTextElement
+	flush := func() {
+		if len(elements) > 0 {
+			result = append(result, slack.NewRichTextBlock("", elements...))
+			elements = nil
+		}
+	}
+
+	for _, block := range blocks {
+		switch {
+		case block.Kind == markdownHeading && block.Level <= 2:
+			flush()
+			text := truncateRunes(markdownPlainText(block.Text), headerTextLimit)
+			result = append(result, slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, text, true, false)))
+		case block.Kind == markdownDivider:
+			flush()
+			result = append(result, slack.NewDividerBlock())
+		default:
+			elements = append(elements, markdownBlockToRichText(block)...)
+		}
+	}
+	flush()
+
+	// 段落の後に続く要素との間に改行を入れる（セクションは連続すると改行なしで表示される）
+	for _, block := range result {
+		if richText, ok := block.(*slack.RichTextBlock); ok {
+			separateRichTextSections(richText.Elements)
+		}
+	}
+	return result
+}
+
+// markdownBlockToRichText converts a block to rich_text elements
+func markdownBlockToRichText(block markdownBlock) []slack.RichTextElement {
+	switch block.Kind {
+	case markdownHeading:
+		// h3 以下は太字の段落にする
+		runs := parseMarkdownInline(block.Text, markdownInline{Style: slack.RichTextSectionTextStyle{Bold: true}})
+		return []slack.RichTextElement{slack.NewRichTextSection(markdownInlinesToRichText(runs)...)}
+	case markdownCode:
+		return []slack.RichTextElement{newRichTextPreformatted(block.Text)}
+	case markdownTable:
+		// 表は rich_text で表現できないため整形済みテキストにする
+		return []slack.RichTextElement{newRichTextPreformatted(formatMarkdownTable(block.Rows))}
+	case markdownDivider:
+		return []slack.RichTextElement{slack.NewRichTextSection(slack.NewRichTextSectionTextElement("──────────", nil))}
+	case markdownQuote:
+		quote := slack.RichTextQuote(*slack.NewRichTextSection(markdownBlocksToInlineRichText(block.Children)...))
+		quote.Type = slack.RTEQuote
+		return []slack.RichTextElement{&quote}
+	case markdownList:
+		return markdownListToRichText(block, 0)
+	default:
+		return []slack.RichTextElement{slack.NewRichTextSection(markdownInlinesToRichText(parseMarkdownInline(block.Text, markdownInline{}))...)}
+	}
+}
+
+// markdownListToRichText converts a list to rich_text_list elements.
+// Nested lists are separate elements with a larger indent, so the outer list is split around them.
+func markdownListToRichText(list markdownBlock, indent int) []slack.RichTextElement {
+	style := slack.RTEListBullet
+	if list.Ordered {
+		style = slack.RTEListOrdered
+	}
+
+	var result []slack.RichTextElement
+	current := slack.NewRichTextList(style, indent)
+	offset := 0
+	if list.Ordered {
+		offset = list.Start - 1
+	}
+	current.Offset = offset
+
+	for _, item := range list.Items {
+		var content []markdownBlock
+		var nested []markdownBlock
+		for _, block := range item {
+			if block.Kind == markdownList {
+				nested = append(nested, block)
+			} else {
+				content = append(content, block)
+			}
+		}
+
+		current.Elements = append(current.Elements, slack.NewRichTextSection(markdownBlocksToInlineRichText(content)...))
+		offset++
+
+		if len(nested) > 0 {
+			result = append(result, current)
+			for _, block := range nested {
+				result = append(result, markdownListToRichText(block, indent+1)...)
+			}
+			current = slack.NewRichTextList(style, indent)
+			current.Offset = offset
+		}
+	}
+	if len(current.Elements) > 0 {
+		result = append(result, current)
+	}
+	return result
+}
+
+// markdownBlocksToInlineRichText flattens blocks into section elements for lists and quotes,
+// which can only contain inline content
+func markdownBlocksToInlineRichText(blocks []markdownBlock) []slack.RichTextSectionElement {
+	var elements []slack.RichTextSectionElement
+	for i, block := range blocks {
+		if i > 0 {
+			elements = append(elements, slack.NewRichTextSectionTextElement("\n", nil))
+		}
+		switch block.Kind {
+		case markdownParagraph:
+			elements = append(elements, markdownInlinesToRichText(parseMarkdownInline(block.Text, markdownInline{}))...)
+		case markdownHeading:
+			elements = append(elements, markdownInlinesToRichText(parseMarkdownInline(block.Text, markdownInline{Style: slack.RichTextSectionTextStyle{Bold: true}}))...)
+		case markdownCode:
+			elements = append(elements, slack.NewRichTextSectionTextElement(block.Text, &slack.RichTextSectionTextStyle{Code: true}))
+		case markdownTable:
+			elements = append(elements, slack.NewRichTextSectionTextElement(formatMarkdownTable(block.Rows), &slack.RichTextSectionTextStyle{Code: true}))
+		default:
+			// 入れ子の引用・リストなどは mrkdwn と同じ見た目のテキストにする
+			elements = append(elements, slack.NewRichTextSectionTextElement(markdownBlockToPlainText(block), nil))
+		}
+	}
+	if len(elements) == 0 {
+		elements = append(elements, slack.NewRichTextSectionTextElement(" ", nil))
+	}
+	return elements
+}
+
+// markdownBlockToPlainText converts a block to text with list bullets and quote markers but no styles
+func markdownBlockToPlainText(block markdownBlock) string {
+	switch block.Kind {
+	case markdownQuote:
+		var parts []string
+		for _, child := range block.Children {
+			parts = append(parts, markdownBlockToPlainText(child))
+		}
+		lines := strings.Split(strings.Join(parts, "\n"), "\n")
+		for i, line := range lines {
+			lines[i] = "> " + line
+		}
+		return strings.Join(lines, "\n")
+	case markdownList:
+		return strings.Join(markdownListLines(block, 0, markdownBlockToPlainText), "\n")
+	case markdownCode:
+		return block.Text
+	case markdownTable:
+		return formatMarkdownTable(block.Rows)
+	case markdownDivider:
+		return "──────────"
+	default:
+		return markdownPlainText(block.Text)
+	}
+}
+
+// markdownInlinesToRichText converts inline runs to rich_text section elements
+func markdownInlinesToRichText(runs []markdownInline) []slack.RichTextSectionElement {
+	var elements []slack.RichTextSectionElement
+	for _, run := range runs {
+		var style *slack.RichTextSectionTextStyle
+		if run.Style != (slack.RichTextSectionTextStyle{}) {
+			s := run.Style
+			style = &s
+		}
+
+		switch {
+		case run.Token != "":
+			elements = append(elements, slackTokenToRichText(run.Token, style))
+		case run.URL != "":
+			elements = append(elements, slack.NewRichTextSectionLinkElement(run.URL, run.Text, style))
+		default:
+			elements = append(elements, slack.NewRichTextSectionTextElement(run.Text, style))
+		}
+	}
+	if len(elements) == 0 {
+		elements = append(elements, slack.NewRichTextSectionTextElement(" ", nil))
+	}
+	return elements
+}
+
+// slackTokenToRichText converts Slack markup such as @U123, #C123|name and !here to a rich_text element
+func slackTokenToRichText(token string, style *slack.RichTextSectionTextStyle) slack.RichTextSectionElement {
+	target, _, _ := strings.Cut(token, "|")
+	switch {
+	case strings.HasPrefix(target, "@"):
+		return slack.NewRichTextSectionUserElement(target[1:], style)
+	case strings.HasPrefix(target, "#"):
+		return slack.NewRichTextSectionChannelElement(target[1:], style)
+	case strings.HasPrefix(target, "!subteam^"):
+		return slack.NewRichTextSectionUserGroupElement(strings.TrimPrefix(target, "!subteam^"))
+	case target == "!here" || target == "!channel" || target == "!everyone":
+		return slack.NewRichTextSectionBroadcastElement(target[1:])
+	default:
+		return slack.NewRichTextSectionTextElement("<"+token+">", style)
+	}
+}
+
+// newRichTextPreformatted creates a rich_text_preformatted element
+func newRichTextPreformatted(text string) *slack.RichTextPreformatted {
+	if text == "" {
+		text = " "
+	}
+	return &slack.RichTextPreformatted{
+		RichTextSection: slack.RichTextSection{
+			Type:     slack.RTEPreformatted,
+			Elements: []slack.RichTextSectionElement{slack.NewRichTextSectionTextElement(text, nil)},
+		},
+	}
+}
+
+// separateRichTextSections appends a newline to sections that are followed by another element
+func separateRichTextSections(elements []slack.RichTextElement) {
+	for i := 0; i < len(elements)-1; i++ {
+		if section, ok := elements[i].(*slack.RichTextSection); ok {
+			section.Elements = append(section.Elements, slack.NewRichTextSectionTextElement("\n", nil))
+		}
+	}
+}
+
+// truncateRunes shortens text to at most limit characters
+func truncateRunes(text string, limit int) string {
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	return string([]rune(text)[:limit-1]) + "…"
+}
@@ -0,0 +1,157 @@
+package slack
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func TestConvertMarkdownText(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		// 見出し
+		{"atx heading", "# Title", "*Title*"},
+		{"atx heading with closing sequence", "### Small #", "*Small*"},
+		{"setext heading", "Title\n=====", "*Title*"},
+		{"setext level 2", "Sub\n---", "*Sub*"},
+
+		// 強調
+		{"emphasis", "**bold** and *it* and ***both***", "*bold* and _it_ and _*both*_"},
+		{"nested emphasis", "**bold _nested_ text**", "*bold* _*nested*_ *text*"},
+		{"strikethrough", "~~gone~~", "~gone~"},
+		{"intraword emphasis", "a**b**c", "a*b*c"},
+		{"intraword underscore", "a_b_c", "a_b_c"},
+		{"code span", "`code *x*`", "`code *x*`"},
+
+		// リンク
+		{"link", "[docs](https://x.com)", "<https://x.com|docs>"},
+		{"link with title", "[docs](<https://x.com> \"t\")", "<https://x.com|docs>"},
+		{"autolink", "<https://x.com>", "<https://x.com>"},
+		{"email autolink", "<mailto:a@b.com>", "<mailto:a@b.com|a@b.com>"},
+		{"image", "![alt](https://x.com/i.png)", "<https://x.com/i.png|alt>"},
+		{"slack tokens pass through", "<@U123> <!here>", "<@U123> <!here>"},
+
+		// リスト
+		{"ordered list", "1. one\n2. two", "1. one\n2. two"},
+		{"ordered list with start", "3) a\n4) b", "3. a\n4. b"},
+		{"nested list", "- a\n  - b\n    - c\n- d", "• a\n    ◦ b\n        ▪ c\n• d"},
+		{"loose list", "* a\n\n* b", "• a\n• b"},
+
+		// 表
+		{"table", "| a | b |\n|---|:-:|\n| 1 | 長い |", "```\na | b\n--+-----\n1 | 長い\n```"},
+
+		// コード
+		{"fenced code", "```go\nx := 1 < 2 && 3 > 1\n```", "```\nx := 1 &lt; 2 &amp;&amp; 3 &gt; 1\n```"},
+		{"indented code", "    indented\n    code", "```\nindented\ncode\n```"},
+
+		// 引用
+		{"blockquote", "> quote\n> more", "> quote more"},
+		{"list in blockquote", "> - item", "> • item"},
+
+		// エスケープ・改行・区切り線
+		{"escape", "a & b < c > d", "a &amp; b &lt; c &gt; d"},
+		{"hard break", "line  \nbreak", "line\nbreak"},
+		{"soft break", "soft\nbreak", "soft break"},
+		{"soft break between cjk", "日本\n語", "日本語"},
+		{"thematic break", "---", "──────────"},
+		{"paragraphs", "one\n\ntwo", "one\n\ntwo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ConvertMarkdown(tt.markdown).Text; got != tt.want {
+				t.Errorf("ConvertMarkdown(%q).Text =\n%q\nwant\n%q", tt.markdown, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertMarkdownBlocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     []slack.MessageBlockType // nil: mrkdwn のテキストのみで送信
+	}{
+		// mrkdwn で表現できるものはブロックを使わない
+		{"paragraph", "**b** c", nil},
+		{"heading only", "# Title", nil},
+		{"code", "```\nx\n```", nil},
+		{"quote", "> quote", nil},
+		{"divider", "text\n\n---", nil},
+
+		// リスト・表・単語内の装飾は rich_text にする
+		{"list", "- a", []slack.MessageBlockType{slack.MBTRichText}},
+		{"table", "| a |\n|---|\n| 1 |", []slack.MessageBlockType{slack.MBTRichText}},
+		{"intraword style", "a**b**c", []slack.MessageBlockType{slack.MBTRichText}},
+		{"code span before a letter", "`x`y", []slack.MessageBlockType{slack.MBTRichText}},
+		{"intraword style in quote", "> quote **b**c", []slack.MessageBlockType{slack.MBTRichText}},
+
+		// h1・h2 は header ブロック、h3 以下は rich_text の太字、区切り線は divider ブロック
+		{"atx heading", "# Title\n\n- a", []slack.MessageBlockType{slack.MBTHeader, slack.MBTRichText}},
+		{"setext heading", "Setext\n===\n\n1. x", []slack.MessageBlockType{slack.MBTHeader, slack.MBTRichText}},
+		{"small heading", "### Small\n\n- a", []slack.MessageBlockType{slack.MBTRichText}},
+		{"divider", "text\n\n---\n\n- a", []slack.MessageBlockType{slack.MBTRichText, slack.MBTDivider, slack.MBTRichText}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []slack.MessageBlockType
+			for _, block := range ConvertMarkdown(tt.markdown).Blocks {
+				got = append(got, block.BlockType())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ConvertMarkdown(%q).Blocks = %v, want %v", tt.markdown, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertMarkdownRichTextList(t *testing.T) {
+	blocks := ConvertMarkdown("1. a\n    - b\n2. c").Blocks
+	if len(blocks) != 1 {
+		t.Fatalf("len(Blocks) = %d, want 1", len(blocks))
+	}
+	richText := blocks[0].(*slack.RichTextBlock)
+
+	// 入れ子のリストは indent の大きい別の要素になり、外側のリストは offset で番号を続ける
+	type list struct {
+		style          slack.RichTextListElementType
+		indent, offset int
+		items          int
+	}
+	var got []list
+	for _, element := range richText.Elements {
+		l, ok := element.(*slack.RichTextList)
+		if !ok {
+			t.Fatalf("element %T, want *slack.RichTextList", element)
+		}
+		got = append(got, list{l.Style, l.Indent, l.Offset, len(l.Elements)})
+	}
+	want := []list{
+		{slack.RTEListOrdered, 0, 0, 1},
+		{slack.RTEListBullet, 1, 0, 1},
+		{slack.RTEListOrdered, 0, 1, 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("lists = %+v, want %+v", got, want)
+	}
+}
+
+func TestConvertMarkdownHeaderTruncated(t *testing.T) {
+	long := ""
+	for i := 0; i < headerTextLimit+10; i++ {
+		long += "あ"
+	}
+	blocks := ConvertMarkdown("# " + long + "\n\n- a").Blocks
+	header, ok := blocks[0].(*slack.HeaderBlock)
+	if !ok {
+		t.Fatalf("Blocks[0] = %T, want *slack.HeaderBlock", blocks[0])
+	}
+	if n := len([]rune(header.Text.Text)); n > headerTextLimit {
+		t.Errorf("header text has %d characters, want at most %d", n, headerTextLimit)
+	}
+}
@@ -0,0 +1,203 @@
+package slack
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// markupTokenRegex matches Slack markup tokens such as <@U123>, <#C123|name> and <https://example.com|label>.
+// Slack escapes "<" and ">" in message text, so a token never contains them.
+var markupTokenRegex = regexp.MustCompile(`<([^<>\n]*)>`)
+
+// htmlEntityReplacer decodes the HTML entities Slack uses to escape message text
+var htmlEntityReplacer = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&")
+
+// MarkupDecoder decodes Slack message markup into the plain text people see in Slack
+type MarkupDecoder struct {
+	// ResolveUser returns the display name for a user ID (empty if unknown)
+	ResolveUser func(userID string) string
+	// ResolveUserGroup returns the handle for a user group ID (empty if unknown)
+	ResolveUserGroup func(groupID string) string
+	// ResolveChannel returns the name for a channel ID (empty if unknown)
+	ResolveChannel func(channelID string) string
+	// Location is used to render <!date^...> tokens without fallback text (nil means local time)
+	Location *time.Location
+	// FormatLink renders a link token (label is empty without one); nil renders "label (url)" as plain text
+	FormatLink func(url, label string) string
+}
+
+// Decode converts Slack markup tokens and HTML entities in text to plain text.
+// Tokens inside code blocks and inline code are left as written.
+func (d *MarkupDecoder) Decode(text string) string {
+	var result strings.Builder
+
+	// コード部分はHTMLエンティティのみデコード
+	last := 0
+	for _, loc := range mrkdwnCodeRegex.FindAllStringIndex(text, -1) {
+		result.WriteString(d.decodeTokens(text[last:loc[0]]))
+		result.WriteString(htmlEntityReplacer.Replace(text[loc[0]:loc[1]]))
+		last = loc[1]
+	}
+	result.WriteString(d.decodeTokens(text[last:]))
+
+	return result.String()
+}
+
+// decodeTokens decodes markup tokens and HTML entities in text without code
+func (d *MarkupDecoder) decodeTokens(text string) string {
+	var result strings.Builder
+
+	// トークンとそれ以外の部分を分けて処理（デコード後の "<" を再解析しないため）
+	last := 0
+	for _, loc := range markupTokenRegex.FindAllStringSubmatchIndex(text, -1) {
+		result.WriteString(htmlEntityReplacer.Replace(text[last:loc[0]]))
+		result.WriteString(d.decodeToken(text[loc[2]:loc[3]]))
+		last = loc[1]
+	}
+	result.WriteString(htmlEntityReplacer.Replace(text[last:]))
+
+	return result.String()
+}
+
+// decodeToken decodes the content of a single <...> token
+func (d *MarkupDecoder) decodeToken(token string) string {
+	// ラベル部分（| 以降）を分離
+	target, label, hasLabel := strings.Cut(token, "|")
+	label = htmlEntityReplacer.Replace(label)
+
+	switch {
+	case target == "":
+		return "<" + htmlEntityReplacer.Replace(token) + ">"
+
+	case strings.HasPrefix(target, "@"):
+		// ユーザーメンション: <@U123> / <@U123|name>
+		userID := target[1:]
+		if name := d.resolve(d.ResolveUser, userID); name != "" {
+			return "@" + name
+		}
+		if hasLabel && label != "" {
+			return "@" + strings.TrimPrefix(label, "@")
+		}
+		return "@" + userID
+
+	case strings.HasPrefix(target, "#"):
+		// チャンネルメンション: <#C123> / <#C123|name>
+		channelID := target[1:]
+		if hasLabel && label != "" {
+			return "#" + label
+		}
+		if name := d.resolve(d.ResolveChannel, channelID); name != "" {
+			return "#" + name
+		}
+		return "#" + channelID
+
+	case strings.HasPrefix(target, "!"):
+		return d.decodeSpecialToken(target[1:], label, hasLabel)
+
+	default:
+		// リンク: <https://example.com> / <https://example.com|label> / <mailto:...|...>
+		if d.FormatLink != nil {
+			return d.FormatLink(htmlEntityReplacer.Replace(target), label)
+		}
+		return decodeLink(htmlEntityReplacer.Replace(target), label, hasLabel)
+	}
+}
+
+// decodeSpecialToken decodes <!...> tokens (subteams, broadcasts and dates)
+func (d *MarkupDecoder) decodeSpecialToken(command, label string, hasLabel bool) string {
+	switch {
+	case strings.HasPrefix(command, "subteam^"):
+		// サブチームメンション: <!subteam^S123> / <!subteam^S123|@handle>
+		groupID := strings.TrimPrefix(command, "subteam^")
+		if handle := d.resolve(d.ResolveUserGroup, groupID); handle != "" {
+			return "@" + handle
+		}
+		if hasLabel && label != "" {
+			return "@" + strings.TrimPrefix(label, "@")
+		}
+		return "@" + groupID
+
+	case strings.HasPrefix(command, "date^"):
+		// 日付: <!date^1392734382^{date_short} {time}^https://example.com|Feb 18, 2014>
+		if hasLabel && label != "" {
+			return label
+		}
+		return d.renderDate(strings.TrimPrefix(command, "date^"))
+
+	case command == "here", command == "channel", command == "everyone":
+		// 一斉メンション: <!here> / <!channel> / <!everyone>
+		return "@" + command
+
+	default:
+		if hasLabel && label != "" {
+			return label
+		}
+		return "@" + command
+	}
+}
+
+// renderDate renders the body of a date token without fallback text
+func (d *MarkupDecoder) renderDate(body string) string {
+	parts := strings.SplitN(body, "^", 3)
+	seconds, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return body
+	}
+
+	loc := d.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	t := time.Unix(seconds, 0).In(loc)
+
+	if len(parts) < 2 {
+		return t.Format("2006-01-02 15:04")
+	}
+
+	return strings.NewReplacer(
+		"{date_num}", t.Format("2006-01-02"),
+		"{date_slash}", t.Format("2006/01/02"),
+		"{date_long_full}", t.Format("Monday, January 2, 2006"),
+		"{date_long_pretty}", t.Format("Monday, January 2, 2006"),
+		"{date_long}", t.Format("Monday, January 2, 2006"),
+		"{date_pretty}", t.Format("January 2, 2006"),
+		"{date_short_pretty}", t.Format("Jan 2, 2006"),
+		"{date_short}", t.Format("Jan 2, 2006"),
+		"{date}", t.Format("January 2, 2006"),
+		"{time_secs}", t.Format("15:04:05"),
+		"{time}", t.Format("15:04"),
+		"{ago}", t.Format("2006-01-02 15:04"),
+	).Replace(parts[1])
+}
+
+// resolve calls the resolver if set
+func (d *MarkupDecoder) resolve(resolver func(string) string, id string) string {
+	if resolver == nil {
+		return ""
+	}
+	return resolver(id)
+}
+
+// decodeLink renders a link token as "label (url)", or just the URL/label when they are the same
+func decodeLink(target, label string, hasLabel bool) string {
+	// mailto: / tel: はスキームを除いて表示
+	display := target
+	for _, scheme := range []string{"mailto:", "tel:"} {
+		if strings.HasPrefix(target, scheme) {
+			display = strings.TrimPrefix(target, scheme)
+		}
+	}
+
+	if !hasLabel || label == "" {
+		return display
+	}
+
+	// Slackが自動リンクした場合（例: <http://example.com|example.com>）はラベルのみ
+	if label == display || label == strings.TrimPrefix(strings.TrimPrefix(target, "https://"), "http://") {
+		return label
+	}
+
+	return label + " (" + display + ")"
+}
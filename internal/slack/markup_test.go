@@ -0,0 +1,84 @@
+package slack
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMarkupDecoderDecode(t *testing.T) {
+	decoder := &MarkupDecoder{
+		ResolveUser: func(userID string) string {
+			return map[string]string{"U111": "taro"}[userID]
+		},
+		ResolveUserGroup: func(groupID string) string {
+			return map[string]string{"S111": "dev-team"}[groupID]
+		},
+		ResolveChannel: func(channelID string) string {
+			return map[string]string{"C111": "general"}[channelID]
+		},
+		Location: time.UTC,
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		// リンク
+		{"url", "see <https://example.com/a?b=1&amp;c=2>", "see https://example.com/a?b=1&c=2"},
+		{"url with label", "<https://example.com|the docs>", "the docs (https://example.com)"},
+		{"auto-linked url", "<https://example.com|example.com>", "example.com"},
+		{"mailto", "<mailto:taro@example.com|taro@example.com>", "taro@example.com"},
+		{"mailto with label", "<mailto:taro@example.com|Taro>", "Taro (taro@example.com)"},
+		{"mailto without label", "<mailto:taro@example.com>", "taro@example.com"},
+
+		// 一斉メンション
+		{"here", "<!here> deploy", "@here deploy"},
+		{"channel", "<!channel>", "@channel"},
+		{"everyone", "<!everyone>", "@everyone"},
+		{"here with label", "<!here|here>", "@here"},
+
+		// 日付
+		{"date with fallback", "<!date^1392734382^{date_short} {time}|Feb 18, 2014 6:39 AM>", "Feb 18, 2014 6:39 AM"},
+		{"date without fallback", "<!date^1392734382^{date_num} {time}>", "2014-02-18 14:39"},
+		{"date without format", "<!date^1392734382>", "2014-02-18 14:39"},
+		{"date with link", "<!date^1392734382^{date_short}^https://example.com>", "Feb 18, 2014"},
+
+		// サブチーム
+		{"subteam resolved", "<!subteam^S111>", "@dev-team"},
+		{"subteam with label", "<!subteam^S999|@designers>", "@designers"},
+		{"subteam unknown", "<!subteam^S999>", "@S999"},
+
+		// ユーザー・チャンネル
+		{"user resolved", "hi <@U111>", "hi @taro"},
+		{"user with label", "<@U999|hanako>", "@hanako"},
+		{"user unknown", "<@U999>", "@U999"},
+		{"channel with label", "<#C999|random>", "#random"},
+		{"channel resolved", "<#C111>", "#general"},
+		{"channel unknown", "<#C999>", "#C999"},
+
+		// HTMLエンティティ
+		{"entities", "a &lt; b &amp;&amp; c &gt; d", "a < b && c > d"},
+		{"escaped token is not decoded", "&lt;@U111&gt;", "<@U111>"},
+		{"double escaped", "&amp;lt;", "&lt;"},
+
+		// コード
+		{"inline code", "run `<@U111> &amp; <!here>` now <@U111>", "run `<@U111> & <!here>` now @taro"},
+		{"code block", "```\n<https://example.com|x> &lt;tag&gt;\n```", "```\n<https://example.com|x> <tag>\n```"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decoder.Decode(tt.text); got != tt.want {
+				t.Errorf("Decode(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkupDecoderWithoutResolvers(t *testing.T) {
+	decoder := &MarkupDecoder{}
+	if got, want := decoder.Decode("<@U111> <#C111> <!subteam^S111>"), "@U111 #C111 @S111"; got != want {
+		t.Errorf("Decode() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,208 @@
+package slack
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// mrkdwnCodeRegex matches code blocks (```...```) and inline code (`...`) in Slack mrkdwn
+var mrkdwnCodeRegex = regexp.MustCompile("(?s)```(.*?)```|`([^`\n]+)`")
+
+// mrkdwn styles only apply when the markers are surrounded by whitespace or punctuation
+var (
+	mrkdwnBoldRegex   = mrkdwnStyleRegex(`\*`)
+	mrkdwnItalicRegex = mrkdwnStyleRegex(`_`)
+	mrkdwnStrikeRegex = mrkdwnStyleRegex(`~`)
+)
+
+// mrkdwnBullets maps the bullet characters Slack uses to Markdown list markers
+var mrkdwnBullets = []struct{ symbol, marker string }{
+	{"• ", "- "},
+	{"◦ ", "  - "},
+	{"▪ ", "    - "},
+}
+
+// markdownHeadingRegex matches lines that CommonMark would render as headings
+var markdownHeadingRegex = regexp.MustCompile(`^(#{1,6})(\s|$)`)
+
+// codePlaceholderRegex matches the placeholders that protect code while converting
+var codePlaceholderRegex = regexp.MustCompile("\x00([0-9]+)\x00")
+
+// mrkdwnStyleRegex builds a regexp for a style marker such as *bold*
+func mrkdwnStyleRegex(marker string) *regexp.Regexp {
+	return regexp.MustCompile(`(^|[\s(\[{"'“‘、。])` + marker + `([^` + marker + `\s](?:[^` + marker + `\n]*[^` + marker + `\s])?)` + marker + `($|[\s)\]}.,:;!?"'”’、。])`)
+}
+
+// MrkdwnToMarkdown converts Slack mrkdwn to CommonMark.
+// Code blocks and inline code are kept verbatim; markup tokens such as mentions are decoded outside code only.
+func MrkdwnToMarkdown(text string, decoder *MarkupDecoder) string {
+	text = strings.ReplaceAll(strings.TrimSpace(text), "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	// コードを退避（装飾の変換・メンションのデコードを行わない）
+	var codes []string
+	text = mrkdwnCodeRegex.ReplaceAllStringFunc(text, func(match string) string {
+		placeholder := "\x00" + strconv.Itoa(len(codes)) + "\x00"
+		if strings.HasPrefix(match, "```") {
+			content := strings.Trim(strings.TrimSuffix(strings.TrimPrefix(match, "```"), "```"), "\n")
+			codes = append(codes, "```\n"+htmlEntityReplacer.Replace(content)+"\n```")
+			// コードブロックは独立した行にする
+			return "\n" + placeholder + "\n"
+		}
+		codes = append(codes, inlineCode(htmlEntityReplacer.Replace(strings.Trim(match, "`"))))
+		return placeholder
+	})
+
+	var lines []string
+	var kinds []markdownLineKind
+	quoteRest := false
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " ")
+		if isCodeBlockPlaceholder(line, codes) {
+			lines = append(lines, line)
+			kinds = append(kinds, markdownCodeLine)
+			continue
+		}
+		if len(kinds) > 0 && kinds[len(kinds)-1] == markdownCodeLine {
+			line = strings.TrimLeft(line, " ")
+		}
+
+		// 引用: "&gt;&gt;&gt;" 以降はメッセージの最後まで、"&gt;" はその行のみ
+		quoted := quoteRest
+		switch {
+		case strings.HasPrefix(line, "&gt;&gt;&gt;"):
+			quoteRest, quoted = true, true
+			line = strings.TrimPrefix(strings.TrimPrefix(line, "&gt;&gt;&gt;"), " ")
+		case strings.HasPrefix(line, "&gt;"):
+			quoted = true
+			line = strings.TrimPrefix(strings.TrimPrefix(line, "&gt;"), " ")
+		}
+
+		line, kind := convertMrkdwnLine(line, decoder)
+		if quoted {
+			line = strings.TrimRight("> "+line, " ")
+			kind = markdownQuoteLine
+		}
+		lines = append(lines, line)
+		kinds = append(kinds, kind)
+	}
+
+	// Slackの改行はそのまま改行として表示されるため、段落内の改行はハードブレークにする。
+	// 引用・リストと通常の行の間は空行で区切る（後続の行が引用・リストに含まれないように）
+	var joined []string
+	for i, line := range lines {
+		joined = append(joined, line)
+		if i == len(lines)-1 || line == "" || lines[i+1] == "" {
+			continue
+		}
+		switch {
+		case kinds[i] == markdownCodeLine || kinds[i+1] == markdownCodeLine:
+		case kinds[i] != kinds[i+1] && kinds[i] != markdownTextLine:
+			joined = append(joined, "")
+		default:
+			joined[len(joined)-1] += "  "
+		}
+	}
+	lines = joined
+
+	result := strings.Join(lines, "\n")
+	result = strings.Trim(result, "\n")
+
+	// 退避したコードを戻す
+	return codePlaceholderRegex.ReplaceAllStringFunc(result, func(placeholder string) string {
+		index, _ := strconv.Atoi(strings.Trim(placeholder, "\x00"))
+		return codes[index]
+	})
+}
+
+// markdownMessageText converts a message body in Slack markup to CommonMark
+func (f *Formatter) markdownMessageText(text string) string {
+	if strings.TrimSpace(text) == "" {
+		return f.cleanMessageText(text)
+	}
+	return MrkdwnToMarkdown(text, f.markupDecoder())
+}
+
+// markdownLineKind classifies converted lines to decide how they are separated
+type markdownLineKind int
+
+const (
+	markdownTextLine markdownLineKind = iota
+	markdownListLine
+	markdownQuoteLine
+	markdownCodeLine
+)
+
+// convertMrkdwnLine converts bullets, styles and markup tokens in a line without code
+func convertMrkdwnLine(line string, decoder *MarkupDecoder) (string, markdownLineKind) {
+	kind := markdownTextLine
+
+	// 箇条書きの記号をリストに変換（◦ ▪ は入れ子）
+	trimmed := strings.TrimLeft(line, " ")
+	for _, bullet := range mrkdwnBullets {
+		if strings.HasPrefix(trimmed, bullet.symbol) {
+			line = bullet.marker + strings.TrimPrefix(trimmed, bullet.symbol)
+			kind = markdownListLine
+			break
+		}
+	}
+
+	// 見出しとして解釈される行頭の # をエスケープ
+	line = markdownHeadingRegex.ReplaceAllString(line, `\$1$2`)
+
+	// 太字・斜体・取り消し線（前後の区切り文字が重なる場合に備えて2回適用）
+	for i := 0; i < 2; i++ {
+		line = mrkdwnBoldRegex.ReplaceAllString(line, "$1**$2**$3")
+		line = mrkdwnItalicRegex.ReplaceAllString(line, "${1}_${2}_$3")
+		line = mrkdwnStrikeRegex.ReplaceAllString(line, "$1~~$2~~$3")
+	}
+
+	// 本文中の "<" がHTMLとして解釈されないようにエスケープしてからデコード（リンクはMarkdownのリンクにする）
+	line = strings.ReplaceAll(line, "&lt;", `\&lt;`)
+	markdownDecoder := *decoder
+	markdownDecoder.FormatLink = markdownLink
+	return markdownDecoder.Decode(line), kind
+}
+
+// markdownLinkLabelEscaper escapes the characters that would end a link label or destination early
+var markdownLinkLabelEscaper = strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`)
+
+// markdownLink renders a link as a CommonMark autolink (<url>) or, with a distinct label, as [label](url)
+func markdownLink(target, label string) string {
+	display := strings.TrimPrefix(target, "mailto:")
+	if label == "" || label == display || label == strings.TrimPrefix(strings.TrimPrefix(target, "https://"), "http://") {
+		// 空白・山括弧を含むURLは自動リンクにできない
+		if !strings.ContainsAny(target, " <>") {
+			if display != target && strings.Contains(display, "@") {
+				return "<" + display + ">" // メールアドレスの自動リンク
+			}
+			return "<" + target + ">"
+		}
+		label = display
+	}
+	destination := strings.NewReplacer(" ", "%20", "<", "%3C", ">", "%3E", "(", `\(`, ")", `\)`).Replace(target)
+	return "[" + markdownLinkLabelEscaper.Replace(label) + "](" + destination + ")"
+}
+
+// isCodeBlockPlaceholder reports whether a line is a placeholder for a code block
+func isCodeBlockPlaceholder(line string, codes []string) bool {
+	match := codePlaceholderRegex.FindStringSubmatch(line)
+	if match == nil || match[0] != line {
+		return false
+	}
+	index, _ := strconv.Atoi(match[1])
+	return strings.HasPrefix(codes[index], "```")
+}
+
+// inlineCode wraps text in backticks, using a longer fence if the text contains backticks
+func inlineCode(text string) string {
+	fence := "`"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+		return fence + " " + text + " " + fence
+	}
+	return fence + text + fence
+}
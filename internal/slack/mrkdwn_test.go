@@ -0,0 +1,72 @@
+package slack
+
+import "testing"
+
+func TestMrkdwnToMarkdown(t *testing.T) {
+	decoder := &MarkupDecoder{
+		ResolveUser: func(userID string) string {
+			return map[string]string{"U111": "taro"}[userID]
+		},
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		// リンク
+		{"bare link", "see <https://example.com/a?b=1&amp;c=2>", "see <https://example.com/a?b=1&c=2>"},
+		{"labelled link", "<https://x.com|the docs>", "[the docs](https://x.com)"},
+		{"auto-linked url", "<https://example.com|example.com>", "<https://example.com>"},
+		{"label with brackets", "<https://x.com|see [1] (draft)>", `[see \[1\] \(draft\)](https://x.com)`},
+		{"destination with parentheses", "<https://en.wikipedia.org/wiki/Go_(language)|Go>", `[Go](https://en.wikipedia.org/wiki/Go_\(language\))`},
+		{"mailto", "<mailto:taro@example.com|taro@example.com>", "<taro@example.com>"},
+		{"mailto with label", "<mailto:taro@example.com|Taro>", "[Taro](mailto:taro@example.com)"},
+		{"link in bold", "*<https://x.com|docs>*", "**[docs](https://x.com)**"},
+
+		// メンション・装飾
+		{"mention", "hi <@U111>", "hi @taro"},
+		{"styles", "*bold* _italic_ ~strike~", "**bold** _italic_ ~~strike~~"},
+		{"heading-like line", "# not a heading", `\# not a heading`},
+		{"escaped html", "a &lt;b&gt; c", `a \<b> c`},
+
+		// コード
+		{"code span", "run `<@U111> *not bold* <https://x.com|x>` now", "run `<@U111> *not bold* <https://x.com|x>` now"},
+		{"code span entities", "`a &amp;&amp; b &lt; c`", "`a && b < c`"},
+		{"code block keeps spaces", "``` `` ```", "```\n `` \n```"},
+		{"fence", "before\n```\n*x* <@U111>\n  indented\n```\nafter", "before\n\n```\n*x* <@U111>\n  indented\n```\n\nafter"},
+		{"fence inline", "```let x = 1```", "```\nlet x = 1\n```"},
+
+		// 改行・リスト・引用
+		{"hard break", "line1\nline2", "line1  \nline2"},
+		{"bullets", "• one\n◦ nested\ntext", "- one  \n  - nested\n\ntext"},
+		{"quote line", "&gt; quoted\nnot quoted", "> quoted\n\nnot quoted"},
+		{"quote rest", "&gt;&gt;&gt; all\nof this", "> all  \n> of this"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MrkdwnToMarkdown(tt.text, decoder); got != tt.want {
+				t.Errorf("MrkdwnToMarkdown(%q) =\n%q\nwant\n%q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownLink(t *testing.T) {
+	tests := []struct {
+		target, label, want string
+	}{
+		{"https://x.com", "", "<https://x.com>"},
+		{"https://x.com", "label", "[label](https://x.com)"},
+		{"https://x.com", "a]b)c", `[a\]b\)c](https://x.com)`},
+		{"https://x.com/a b", "", "[https://x.com/a b](https://x.com/a%20b)"},
+		{"mailto:a@example.com", "", "<a@example.com>"},
+	}
+
+	for _, tt := range tests {
+		if got := markdownLink(tt.target, tt.label); got != tt.want {
+			t.Errorf("markdownLink(%q, %q) = %q, want %q", tt.target, tt.label, got, tt.want)
+		}
+	}
+}
@@ -1,223 +1,315 @@
 package slack
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
-)
 
-// ThreadInfo contains parsed information from a Slack thread URL
-type ThreadInfo struct {
-	ChannelID string
-	Timestamp string
-}
+	"github.com/shellme/slack-tool/internal/i18n"
+)
 
-// ChannelInfo contains parsed information from a Slack channel URL
-type ChannelInfo struct {
-	ChannelID string
-}
+// URLKind represents what a Slack URL points to
+type URLKind string
+
+const (
+	// URLChannel is a channel, e.g. https://your-workspace.slack.com/archives/C12345678
+	URLChannel URLKind = "channel"
+	// URLMessage is a top-level message or thread parent, e.g. .../archives/C12345678/p1234567890123456
+	URLMessage URLKind = "message"
+	// URLReply is a thread reply, e.g. .../archives/C12345678/p1234567890123456?thread_ts=1234567890.000100
+	URLReply URLKind = "reply"
+	// URLFile is a shared file, e.g. .../files/U12345678/F12345678/report.pdf
+	URLFile URLKind = "file"
+	// URLUser is a user profile, e.g. .../team/U12345678
+	URLUser URLKind = "user"
+)
 
-// ThreadURLInfo contains parsed information from a Slack thread URL
-type ThreadURLInfo struct {
+// SlackURL is the parsed form of a Slack URL
+type SlackURL struct {
+	Kind            URLKind
+	Host            string // ワークスペースのホスト（例: your-workspace.slack.com、slack:// の場合は空）
+	TeamID          string // app.slack.com・slack:// の場合のワークスペースID
 	ChannelID       string
-	Timestamp       string
-	ThreadTimestamp string // スレッド返信URLの場合は thread_ts パラメータ
+	Timestamp       string // メッセージのタイムスタンプ（例: 1234567890.123456）
+	ThreadTimestamp string // スレッドの親メッセージのタイムスタンプ（返信・スレッドの場合）
+	FileID          string
+	UserID          string
 }
 
-// ParseSlackURL parses a Slack thread URL and extracts channel ID and timestamp
-func ParseSlackURL(url string) (*ThreadInfo, error) {
-	// Slack URLの正規表現パターン
-	// 例: https://your-workspace.slack.com/archives/C12345678/p1234567890123456
-	pattern := `https://[^/]+\.slack\.com/archives/([A-Z0-9]+)/p(\d+)`
-	re, err := regexp.Compile(pattern)
-	if err != nil {
-		return nil, fmt.Errorf("正規表現のコンパイルに失敗しました: %v", err)
-	}
-
-	matches := re.FindStringSubmatch(url)
-	if len(matches) != 3 {
-		return nil, fmt.Errorf("無効なSlackスレッドURLです。正しい形式: https://your-workspace.slack.com/archives/C12345678/p1234567890123456")
-	}
-
-	channelID := matches[1]
-	timestampStr := matches[2]
+var (
+	// idPattern matches Slack IDs such as C12345678, T12345678 and U12345678
+	idPattern = regexp.MustCompile(`^[A-Z][A-Z0-9]+$`)
+	// permalinkTimestampPattern matches the message part of a permalink, e.g. p1234567890123456
+	permalinkTimestampPattern = regexp.MustCompile(`^p(\d+)$`)
+	// timestampPattern matches a Slack timestamp, e.g. 1234567890.123456
+	timestampPattern = regexp.MustCompile(`^\d{10}\.\d{6}$`)
+)
 
-	// タイムスタンプの形式を変換
-	// p1234567890123456 -> 1234567890.123456
-	if len(timestampStr) < 10 {
-		return nil, fmt.Errorf("無効なタイムスタンプです: %s", timestampStr)
+// ParseURL parses a Slack URL into a typed result. Supported forms:
+//
+//	https://<workspace>.slack.com/archives/<channel>[/p<ts>][?thread_ts=<ts>]  (Enterprise Grid hosts included)
+//	https://<workspace>.slack.com/team/<user>
+//	https://<workspace>.slack.com/files/<user>/<file>[/<name>]
+//	https://app.slack.com/client/<team>/<channel>[/thread/<channel>-<ts>][/user_profile/<user>]
+//	slack://channel?team=<team>&id=<channel>[&message=<ts>][&thread_ts=<ts>]
+//	slack://user?team=<team>&id=<user>, slack://file?team=<team>&id=<file>
+//
+// Query strings and fragments that are not used are ignored.
+func ParseURL(rawURL string) (*SlackURL, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, &invalidURLError{rawURL}
 	}
 
-	// 秒部分とマイクロ秒部分に分割
-	seconds := timestampStr[:10]
-	microseconds := timestampStr[10:]
-
-	// マイクロ秒部分を6桁に調整（SlackのAPIが期待する形式）
-	if len(microseconds) > 6 {
-		microseconds = microseconds[:6]
-	} else {
-		// 6桁にパディング
-		for len(microseconds) < 6 {
-			microseconds += "0"
+	var result *SlackURL
+	switch {
+	case u.Scheme == "slack":
+		result, err = parseDeepLink(u)
+	case (u.Scheme == "https" || u.Scheme == "http") && isSlackHost(u.Hostname()):
+		segments := strings.FieldsFunc(u.Path, func(r rune) bool { return r == '/' })
+		if u.Hostname() == "app.slack.com" {
+			result, err = parseClientPath(segments)
+		} else {
+			result, err = parseWorkspacePath(segments, u.Query())
+			if result != nil {
+				result.Host = u.Hostname()
+			}
 		}
+	default:
+		return nil, &invalidURLError{rawURL}
 	}
-
-	// 数値として有効かチェック
-	if _, err := strconv.ParseInt(seconds, 10, 64); err != nil {
-		return nil, fmt.Errorf("無効なタイムスタンプの秒部分です: %s", seconds)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, &invalidURLError{rawURL}
 	}
+	return result, nil
+}
 
-	timestamp := seconds + "." + microseconds
+// invalidURLError is returned by ParseURL for URLs that are not in a supported form
+type invalidURLError struct {
+	url string
+}
 
-	return &ThreadInfo{
-		ChannelID: channelID,
-		Timestamp: timestamp,
-	}, nil
+func (e *invalidURLError) Error() string {
+	return fmt.Sprintf(i18n.T("無効なSlackのURLです: %s"), e.url)
 }
 
-// ParseChannelURL parses a Slack channel URL and extracts channel ID
-func ParseChannelURL(url string) (*ChannelInfo, error) {
-	// SlackチャンネルURLの正規表現パターン
-	// 例: https://your-workspace.slack.com/archives/C12345678
-	pattern := `https://[^/]+\.slack\.com/archives/([A-Z0-9]+)/?$`
-	re, err := regexp.Compile(pattern)
-	if err != nil {
-		return nil, fmt.Errorf("正規表現のコンパイルに失敗しました: %v", err)
-	}
+// isSlackHost reports whether host is slack.com or one of its subdomains (workspaces, Enterprise Grid, app)
+func isSlackHost(host string) bool {
+	host = strings.ToLower(host)
+	return host == "slack.com" || strings.HasSuffix(host, ".slack.com")
+}
 
-	matches := re.FindStringSubmatch(url)
-	if len(matches) != 2 {
-		return nil, fmt.Errorf("無効なSlackチャンネルURLです。正しい形式: https://your-workspace.slack.com/archives/C12345678")
+// parseWorkspacePath parses the path of a workspace URL (archives, team and files)
+func parseWorkspacePath(segments []string, query url.Values) (*SlackURL, error) {
+	if len(segments) < 2 {
+		return nil, nil
 	}
 
-	channelID := matches[1]
+	switch segments[0] {
+	case "archives":
+		if !idPattern.MatchString(segments[1]) {
+			return nil, nil
+		}
+		result := &SlackURL{Kind: URLChannel, ChannelID: segments[1]}
+		if len(segments) == 2 {
+			return result, nil
+		}
 
-	return &ChannelInfo{
-		ChannelID: channelID,
-	}, nil
-}
+		// /archives/<channel>/p<ts> の後に続くパスは受け付けない
+		matches := permalinkTimestampPattern.FindStringSubmatch(segments[2])
+		if matches == nil || len(segments) > 3 {
+			return nil, nil
+		}
+		timestamp, err := permalinkTimestamp(matches[1])
+		if err != nil {
+			return nil, err
+		}
+		result.Kind, result.Timestamp = URLMessage, timestamp
+
+		// スレッド返信URLの場合は thread_ts パラメータを抽出
+		if threadTimestamp := query.Get("thread_ts"); threadTimestamp != "" {
+			if !timestampPattern.MatchString(threadTimestamp) {
+				return nil, fmt.Errorf(i18n.T("無効なタイムスタンプです: %s"), threadTimestamp)
+			}
+			result.ThreadTimestamp = threadTimestamp
+			if threadTimestamp != timestamp {
+				result.Kind = URLReply
+			}
+		}
+		return result, nil
 
-// ValidateChannelID validates if the channel ID format is correct
-func ValidateChannelID(channelID string) error {
-	if channelID == "" {
-		return fmt.Errorf("チャンネルIDが空です")
-	}
+	case "team":
+		if idPattern.MatchString(segments[1]) {
+			return &SlackURL{Kind: URLUser, UserID: segments[1]}, nil
+		}
 
-	// チャンネルIDの形式をチェック（C, G, Dで始まる）
-	if !strings.HasPrefix(channelID, "C") && !strings.HasPrefix(channelID, "G") && !strings.HasPrefix(channelID, "D") {
-		return fmt.Errorf("無効なチャンネルID形式です: %s", channelID)
+	case "files":
+		// /files/<user>/<file>/<name>
+		if len(segments) >= 3 && idPattern.MatchString(segments[1]) && idPattern.MatchString(segments[2]) {
+			return &SlackURL{Kind: URLFile, UserID: segments[1], FileID: segments[2]}, nil
+		}
 	}
-
-	return nil
+	return nil, nil
 }
 
-// ParseThreadURL parses a Slack thread URL and extracts channel ID and timestamp
-func ParseThreadURL(url string) (*ThreadURLInfo, error) {
-	// スレッド返信URLの場合は thread_ts パラメータを抽出
-	if strings.Contains(url, "thread_ts=") {
-		return parseThreadReplyURL(url)
+// parseClientPath parses the path of an app.slack.com/client URL
+func parseClientPath(segments []string) (*SlackURL, error) {
+	// /client/<team>/<channel>/...
+	if len(segments) < 3 || segments[0] != "client" || !idPattern.MatchString(segments[1]) || !idPattern.MatchString(segments[2]) {
+		return nil, nil
 	}
-
-	// 通常のスレッドURLの解析
-	// SlackスレッドURLの正規表現パターン
-	// 例: https://your-workspace.slack.com/archives/C12345678/p1234567890123456
-	pattern := `https://[^/]+\.slack\.com/archives/([A-Z0-9]+)/p(\d+)`
-	re, err := regexp.Compile(pattern)
-	if err != nil {
-		return nil, fmt.Errorf("正規表現のコンパイルに失敗しました: %v", err)
+	result := &SlackURL{Kind: URLChannel, TeamID: segments[1], ChannelID: segments[2]}
+
+	for i := 3; i+1 < len(segments); i += 2 {
+		switch segments[i] {
+		case "thread":
+			// /thread/<channel>-<ts>（スレッドを開いた状態）
+			_, timestamp, ok := strings.Cut(segments[i+1], "-")
+			if !ok || !timestampPattern.MatchString(timestamp) {
+				return nil, fmt.Errorf(i18n.T("無効なタイムスタンプです: %s"), segments[i+1])
+			}
+			result.Kind, result.Timestamp, result.ThreadTimestamp = URLMessage, timestamp, timestamp
+		case "user_profile":
+			if idPattern.MatchString(segments[i+1]) {
+				return &SlackURL{Kind: URLUser, TeamID: result.TeamID, UserID: segments[i+1]}, nil
+			}
+		}
 	}
+	return result, nil
+}
 
-	matches := re.FindStringSubmatch(url)
-	if len(matches) != 3 {
-		return nil, fmt.Errorf("無効なSlackスレッドURLです。正しい形式: https://your-workspace.slack.com/archives/C12345678/p1234567890123456")
+// parseDeepLink parses a slack:// deep link
+func parseDeepLink(u *url.URL) (*SlackURL, error) {
+	query := u.Query()
+	id := query.Get("id")
+	if !idPattern.MatchString(id) {
+		return nil, nil
 	}
+	result := &SlackURL{TeamID: query.Get("team")}
+
+	switch u.Host {
+	case "channel":
+		result.Kind, result.ChannelID = URLChannel, id
+		if message := query.Get("message"); message != "" {
+			if !timestampPattern.MatchString(message) {
+				return nil, fmt.Errorf(i18n.T("無効なタイムスタンプです: %s"), message)
+			}
+			result.Kind, result.Timestamp = URLMessage, message
+		}
+		if threadTimestamp := query.Get("thread_ts"); threadTimestamp != "" && result.Timestamp != "" {
+			if !timestampPattern.MatchString(threadTimestamp) {
+				return nil, fmt.Errorf(i18n.T("無効なタイムスタンプです: %s"), threadTimestamp)
+			}
+			result.ThreadTimestamp = threadTimestamp
+			if threadTimestamp != result.Timestamp {
+				result.Kind = URLReply
+			}
+		}
+	case "user":
+		result.Kind, result.UserID = URLUser, id
+	case "file":
+		result.Kind, result.FileID = URLFile, id
+	default:
+		return nil, nil
+	}
+	return result, nil
+}
 
-	channelID := matches[1]
-	timestampStr := matches[2]
-
-	// タイムスタンプの形式を変換
-	// p1760786585959009 -> 1760786585.959009
-	if len(timestampStr) < 10 {
-		return nil, fmt.Errorf("無効なタイムスタンプです: %s", timestampStr)
+// permalinkTimestamp converts the digits of a permalink to a Slack timestamp
+// p1234567890123456 -> 1234567890.123456
+func permalinkTimestamp(digits string) (string, error) {
+	if len(digits) < 10 {
+		return "", fmt.Errorf(i18n.T("無効なタイムスタンプです: %s"), digits)
 	}
 
 	// 秒部分とマイクロ秒部分に分割
-	seconds := timestampStr[:10]
-	microseconds := timestampStr[10:]
+	seconds := digits[:10]
+	microseconds := digits[10:]
 
 	// マイクロ秒部分を6桁に調整（SlackのAPIが期待する形式）
 	if len(microseconds) > 6 {
 		microseconds = microseconds[:6]
 	} else {
-		// 6桁にパディング
-		for len(microseconds) < 6 {
-			microseconds += "0"
-		}
+		microseconds += strings.Repeat("0", 6-len(microseconds))
 	}
 
 	// 数値として有効かチェック
 	if _, err := strconv.ParseInt(seconds, 10, 64); err != nil {
-		return nil, fmt.Errorf("無効なタイムスタンプの秒部分です: %s", seconds)
+		return "", fmt.Errorf(i18n.T("無効なタイムスタンプの秒部分です: %s"), seconds)
 	}
 
-	timestamp := seconds + "." + microseconds
+	return seconds + "." + microseconds, nil
+}
 
-	return &ThreadURLInfo{
-		ChannelID: channelID,
-		Timestamp: timestamp,
-	}, nil
+// ThreadInfo contains parsed information from a Slack thread URL
+type ThreadInfo struct {
+	ChannelID string
+	Timestamp string
+}
+
+// ChannelInfo contains parsed information from a Slack channel URL
+type ChannelInfo struct {
+	ChannelID string
+}
+
+// ThreadURLInfo contains parsed information from a Slack thread URL
+type ThreadURLInfo struct {
+	ChannelID       string
+	Timestamp       string
+	ThreadTimestamp string // スレッド返信URLの場合は thread_ts パラメータ
 }
 
-// parseThreadReplyURL parses a Slack thread reply URL and extracts channel ID and thread timestamp
-func parseThreadReplyURL(url string) (*ThreadURLInfo, error) {
-	// スレッド返信URLの正規表現パターン
-	// 例: https://your-workspace.slack.com/archives/C12345678/p1234567890123456?thread_ts=1760786585.959009&cid=C12345678
-	pattern := `https://[^/]+\.slack\.com/archives/([A-Z0-9]+)/p(\d+).*[?&]thread_ts=([0-9.]+)`
-	re, err := regexp.Compile(pattern)
+// ParseSlackURL parses a Slack message URL and extracts channel ID and timestamp
+func ParseSlackURL(rawURL string) (*ThreadInfo, error) {
+	info, err := ParseThreadURL(rawURL)
 	if err != nil {
-		return nil, fmt.Errorf("正規表現のコンパイルに失敗しました: %v", err)
+		return nil, err
 	}
+	return &ThreadInfo{ChannelID: info.ChannelID, Timestamp: info.Timestamp}, nil
+}
 
-	matches := re.FindStringSubmatch(url)
-	if len(matches) != 4 {
-		return nil, fmt.Errorf("無効なSlackスレッド返信URLです。正しい形式: https://your-workspace.slack.com/archives/C12345678/p1234567890123456?thread_ts=1760786585.959009&cid=C12345678")
+// ParseChannelURL parses a Slack channel URL and extracts channel ID
+func ParseChannelURL(rawURL string) (*ChannelInfo, error) {
+	parsed, err := ParseURL(rawURL)
+	if err != nil || parsed.Kind != URLChannel {
+		return nil, errors.New(i18n.T("無効なSlackチャンネルURLです。正しい形式: https://your-workspace.slack.com/archives/C12345678"))
 	}
+	return &ChannelInfo{ChannelID: parsed.ChannelID}, nil
+}
 
-	channelID := matches[1]
-	messageTimestampStr := matches[2]
-	threadTimestamp := matches[3]
-
-	// メッセージのタイムスタンプを変換
-	// p1760949917358289 -> 1760949917.358289
-	if len(messageTimestampStr) < 10 {
-		return nil, fmt.Errorf("無効なメッセージタイムスタンプです: %s", messageTimestampStr)
+// ParseThreadURL parses a Slack message or thread reply URL and extracts channel ID and timestamps
+func ParseThreadURL(rawURL string) (*ThreadURLInfo, error) {
+	parsed, err := ParseURL(rawURL)
+	var invalid *invalidURLError
+	if err != nil && !errors.As(err, &invalid) {
+		return nil, err // タイムスタンプの形式の誤り
 	}
-
-	// 秒部分とマイクロ秒部分に分割
-	seconds := messageTimestampStr[:10]
-	microseconds := messageTimestampStr[10:]
-
-	// マイクロ秒部分を6桁に調整
-	if len(microseconds) > 6 {
-		microseconds = microseconds[:6]
-	} else {
-		// 6桁にパディング
-		for len(microseconds) < 6 {
-			microseconds += "0"
-		}
+	if err != nil || (parsed.Kind != URLMessage && parsed.Kind != URLReply) {
+		return nil, errors.New(i18n.T("無効なSlackスレッドURLです。正しい形式: https://your-workspace.slack.com/archives/C12345678/p1234567890123456"))
 	}
+	return &ThreadURLInfo{
+		ChannelID:       parsed.ChannelID,
+		Timestamp:       parsed.Timestamp,       // 実際のメッセージのタイムスタンプ
+		ThreadTimestamp: parsed.ThreadTimestamp, // スレッドのタイムスタンプ
+	}, nil
+}
 
-	// 数値として有効かチェック
-	if _, err := strconv.ParseInt(seconds, 10, 64); err != nil {
-		return nil, fmt.Errorf("無効なメッセージタイムスタンプの秒部分です: %s", seconds)
+// ValidateChannelID validates if the channel ID format is correct
+func ValidateChannelID(channelID string) error {
+	if channelID == "" {
+		return errors.New(i18n.T("チャンネルIDが空です"))
 	}
 
-	messageTimestamp := seconds + "." + microseconds
+	// チャンネルIDの形式をチェック（C, G, Dで始まる）
+	if !strings.HasPrefix(channelID, "C") && !strings.HasPrefix(channelID, "G") && !strings.HasPrefix(channelID, "D") {
+		return fmt.Errorf(i18n.T("無効なチャンネルID形式です: %s"), channelID)
+	}
 
-	return &ThreadURLInfo{
-		ChannelID:       channelID,
-		Timestamp:       messageTimestamp, // 実際のメッセージのタイムスタンプ
-		ThreadTimestamp: threadTimestamp,  // スレッドのタイムスタンプ
-	}, nil
+	return nil
 }
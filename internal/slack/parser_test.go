@@ -0,0 +1,170 @@
+package slack
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want *SlackURL // nil: 無効なURL
+	}{
+		// archives
+		{"channel", "https://ws.slack.com/archives/C12345678",
+			&SlackURL{Kind: URLChannel, Host: "ws.slack.com", ChannelID: "C12345678"}},
+		{"channel with trailing slash", "https://ws.slack.com/archives/C12345678/",
+			&SlackURL{Kind: URLChannel, Host: "ws.slack.com", ChannelID: "C12345678"}},
+		{"message", "https://ws.slack.com/archives/C12345678/p1234567890123456",
+			&SlackURL{Kind: URLMessage, Host: "ws.slack.com", ChannelID: "C12345678", Timestamp: "1234567890.123456"}},
+		{"message with query and fragment", "https://ws.slack.com/archives/C12345678/p1234567890123456/?cid=C12345678#x",
+			&SlackURL{Kind: URLMessage, Host: "ws.slack.com", ChannelID: "C12345678", Timestamp: "1234567890.123456"}},
+		{"thread parent", "https://ws.slack.com/archives/C12345678/p1234567890123456?thread_ts=1234567890.123456&cid=C12345678",
+			&SlackURL{Kind: URLMessage, Host: "ws.slack.com", ChannelID: "C12345678", Timestamp: "1234567890.123456", ThreadTimestamp: "1234567890.123456"}},
+		{"thread reply", "https://ws.slack.com/archives/C12345678/p1234567890123456?thread_ts=1234567890.000100",
+			&SlackURL{Kind: URLReply, Host: "ws.slack.com", ChannelID: "C12345678", Timestamp: "1234567890.123456", ThreadTimestamp: "1234567890.000100"}},
+		{"short permalink", "https://ws.slack.com/archives/C12345678/p1234567890",
+			&SlackURL{Kind: URLMessage, Host: "ws.slack.com", ChannelID: "C12345678", Timestamp: "1234567890.000000"}},
+		{"enterprise grid", "https://acme.enterprise.slack.com/archives/C12345678/p1234567890123456",
+			&SlackURL{Kind: URLMessage, Host: "acme.enterprise.slack.com", ChannelID: "C12345678", Timestamp: "1234567890.123456"}},
+		{"http and surrounding spaces", "  http://ws.slack.com/archives/G12345678  ",
+			&SlackURL{Kind: URLChannel, Host: "ws.slack.com", ChannelID: "G12345678"}},
+
+		// team・files
+		{"user profile", "https://ws.slack.com/team/U12345678",
+			&SlackURL{Kind: URLUser, Host: "ws.slack.com", UserID: "U12345678"}},
+		{"file", "https://ws.slack.com/files/U12345678/F12345678/report.pdf",
+			&SlackURL{Kind: URLFile, Host: "ws.slack.com", UserID: "U12345678", FileID: "F12345678"}},
+		{"file without name", "https://ws.slack.com/files/U12345678/F12345678",
+			&SlackURL{Kind: URLFile, Host: "ws.slack.com", UserID: "U12345678", FileID: "F12345678"}},
+
+		// app.slack.com
+		{"client channel", "https://app.slack.com/client/T12345678/C12345678",
+			&SlackURL{Kind: URLChannel, TeamID: "T12345678", ChannelID: "C12345678"}},
+		{"client thread", "https://app.slack.com/client/T12345678/C12345678/thread/C12345678-1234567890.123456",
+			&SlackURL{Kind: URLMessage, TeamID: "T12345678", ChannelID: "C12345678", Timestamp: "1234567890.123456", ThreadTimestamp: "1234567890.123456"}},
+		{"client user profile", "https://app.slack.com/client/T12345678/C12345678/user_profile/U12345678",
+			&SlackURL{Kind: URLUser, TeamID: "T12345678", UserID: "U12345678"}},
+
+		// slack://
+		{"deep link channel", "slack://channel?team=T12345678&id=C12345678",
+			&SlackURL{Kind: URLChannel, TeamID: "T12345678", ChannelID: "C12345678"}},
+		{"deep link message", "slack://channel?team=T12345678&id=C12345678&message=1234567890.123456",
+			&SlackURL{Kind: URLMessage, TeamID: "T12345678", ChannelID: "C12345678", Timestamp: "1234567890.123456"}},
+		{"deep link reply", "slack://channel?team=T12345678&id=C12345678&message=1234567890.123456&thread_ts=1234567890.000100",
+			&SlackURL{Kind: URLReply, TeamID: "T12345678", ChannelID: "C12345678", Timestamp: "1234567890.123456", ThreadTimestamp: "1234567890.000100"}},
+		{"deep link user", "slack://user?team=T12345678&id=U12345678",
+			&SlackURL{Kind: URLUser, TeamID: "T12345678", UserID: "U12345678"}},
+		{"deep link file", "slack://file?team=T12345678&id=F12345678",
+			&SlackURL{Kind: URLFile, TeamID: "T12345678", FileID: "F12345678"}},
+
+		// 無効なURL
+		{"not slack", "https://example.com/archives/C12345678", nil},
+		{"lookalike host", "https://slack.com.example.com/archives/C12345678", nil},
+		{"unsupported scheme", "ftp://ws.slack.com/archives/C12345678", nil},
+		{"lowercase channel", "https://ws.slack.com/archives/c12345678", nil},
+		{"no channel", "https://ws.slack.com/archives", nil},
+		{"not a permalink", "https://ws.slack.com/archives/C12345678/1234567890123456", nil},
+		{"extra path after permalink", "https://ws.slack.com/archives/C12345678/p1234567890123456/garbage", nil},
+		{"unknown path", "https://ws.slack.com/messages/C12345678", nil},
+		{"client without channel", "https://app.slack.com/client/T12345678", nil},
+		{"deep link without id", "slack://channel?team=T12345678", nil},
+		{"unknown deep link", "slack://open?team=T12345678&id=C12345678", nil},
+		{"empty", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseURL(tt.url)
+			if tt.want == nil {
+				var invalid *invalidURLError
+				if !errors.As(err, &invalid) {
+					t.Errorf("ParseURL(%q) = %+v, %v, want an invalid URL error", tt.url, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseURL(%q) error: %v", tt.url, err)
+			}
+			if *got != *tt.want {
+				t.Errorf("ParseURL(%q) =\n%+v\nwant\n%+v", tt.url, *got, *tt.want)
+			}
+		})
+	}
+}
+
+func TestParseURLInvalidTimestamp(t *testing.T) {
+	// 形式は正しいがタイムスタンプが不正なURLは、無効なURLではなくタイムスタンプのエラー
+	for _, rawURL := range []string{
+		"https://ws.slack.com/archives/C12345678/p123456789",
+		"https://ws.slack.com/archives/C12345678/p1234567890123456?thread_ts=123",
+		"https://app.slack.com/client/T12345678/C12345678/thread/C12345678-123",
+		"slack://channel?team=T12345678&id=C12345678&message=abc",
+	} {
+		_, err := ParseURL(rawURL)
+		var invalid *invalidURLError
+		if err == nil || errors.As(err, &invalid) {
+			t.Errorf("ParseURL(%q) error = %v, want a timestamp error", rawURL, err)
+		}
+	}
+}
+
+func FuzzParseURL(f *testing.F) {
+	for _, seed := range []string{
+		"https://ws.slack.com/archives/C12345678",
+		"https://ws.slack.com/archives/C12345678/p1234567890123456?thread_ts=1234567890.000100",
+		"https://acme.enterprise.slack.com/archives/C12345678/p1234567890123456/",
+		"https://ws.slack.com/team/U12345678",
+		"https://ws.slack.com/files/U12345678/F12345678/report.pdf",
+		"https://app.slack.com/client/T12345678/C12345678/thread/C12345678-1234567890.123456",
+		"https://app.slack.com/client/T12345678/C12345678/user_profile/U12345678",
+		"slack://channel?team=T12345678&id=C12345678&message=1234567890.123456&thread_ts=1234567890.000100",
+		"slack://file?id=F12345678",
+		"",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, rawURL string) {
+		parsed, err := ParseURL(rawURL)
+		if err != nil {
+			if parsed != nil {
+				t.Fatalf("ParseURL(%q) returned %+v with error %v", rawURL, parsed, err)
+			}
+			return
+		}
+		if parsed == nil {
+			t.Fatalf("ParseURL(%q) returned nil without an error", rawURL)
+		}
+
+		// 種類ごとに必要なIDがそろっている
+		validTimestamp := func(ts string) bool { return timestampPattern.MatchString(ts) }
+		switch parsed.Kind {
+		case URLChannel:
+			if !idPattern.MatchString(parsed.ChannelID) || parsed.Timestamp != "" {
+				t.Fatalf("ParseURL(%q) = %+v: invalid channel", rawURL, parsed)
+			}
+		case URLMessage:
+			if !idPattern.MatchString(parsed.ChannelID) || !validTimestamp(parsed.Timestamp) ||
+				(parsed.ThreadTimestamp != "" && parsed.ThreadTimestamp != parsed.Timestamp) {
+				t.Fatalf("ParseURL(%q) = %+v: invalid message", rawURL, parsed)
+			}
+		case URLReply:
+			if !idPattern.MatchString(parsed.ChannelID) || !validTimestamp(parsed.Timestamp) ||
+				!validTimestamp(parsed.ThreadTimestamp) || parsed.ThreadTimestamp == parsed.Timestamp {
+				t.Fatalf("ParseURL(%q) = %+v: invalid reply", rawURL, parsed)
+			}
+		case URLFile:
+			if !idPattern.MatchString(parsed.FileID) {
+				t.Fatalf("ParseURL(%q) = %+v: invalid file", rawURL, parsed)
+			}
+		case URLUser:
+			if !idPattern.MatchString(parsed.UserID) {
+				t.Fatalf("ParseURL(%q) = %+v: invalid user", rawURL, parsed)
+			}
+		default:
+			t.Fatalf("ParseURL(%q) returned unknown kind %q", rawURL, parsed.Kind)
+		}
+	})
+}
@@ -0,0 +1,286 @@
+package slack
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/shellme/slack-tool/internal/i18n"
+)
+
+// Renderer renders a document in a specific output format
+type Renderer interface {
+	Render(w io.Writer, doc *Document) error
+}
+
+// RenderFormats lists the output formats accepted by NewRenderer
+var RenderFormats = []string{"text", "markdown", "json", "jsonl", "csv", "html"}
+
+// NewRenderer returns the renderer for an output format (text / markdown / json / jsonl / csv / html)
+func NewRenderer(format string) (Renderer, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", "text", "txt":
+		return &TextRenderer{}, nil
+	case "markdown", "md":
+		return &MarkdownRenderer{}, nil
+	case "json":
+		return &JSONRenderer{}, nil
+	case "jsonl", "ndjson":
+		return &JSONLRenderer{}, nil
+	case "csv":
+		return &CSVRenderer{}, nil
+	case "html", "htm":
+		return &HTMLRenderer{}, nil
+	default:
+		return nil, fmt.Errorf(i18n.T("サポートされていない出力形式です: %s（%s のいずれかを指定してください）"), format, strings.Join(RenderFormats, ", "))
+	}
+}
+
+// RenderString renders a document to a string
+func RenderString(r Renderer, doc *Document) (string, error) {
+	var result strings.Builder
+	if err := r.Render(&result, doc); err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
+
+// documentHeading returns the title with the part number and fetch date, e.g. "Slackスレッドの内容 (2006/01/02 取得)"
+func documentHeading(doc *Document) string {
+	fetched := fmt.Sprintf(i18n.T("%s 取得"), doc.FetchedAt.Format("2006/01/02"))
+	if doc.Parts > 1 {
+		return fmt.Sprintf("%s [%d/%d] (%s)", doc.Title, doc.Part, doc.Parts, fetched)
+	}
+	return fmt.Sprintf("%s (%s)", doc.Title, fetched)
+}
+
+// messageNotes returns annotations such as "対象のメッセージ", "チャンネルにも投稿" and "編集済み: 日時"
+func messageNotes(msg DocumentMessage) []string {
+	var notes []string
+	if msg.Target {
+		notes = append(notes, i18n.T("対象のメッセージ"))
+	}
+	if msg.Broadcast {
+		notes = append(notes, i18n.T("チャンネルにも投稿"))
+	}
+	if msg.Edited {
+		if msg.EditedText != "" {
+			notes = append(notes, i18n.T("編集済み: ")+msg.EditedText)
+		} else {
+			notes = append(notes, i18n.T("編集済み"))
+		}
+	}
+	return notes
+}
+
+// TextRenderer renders the plain text format suited to AI input
+type TextRenderer struct{}
+
+// Render writes the document as plain text
+func (r *TextRenderer) Render(w io.Writer, doc *Document) error {
+	var result strings.Builder
+
+	// ヘッダーを追加
+	result.WriteString("--- " + documentHeading(doc) + " ---\n")
+	if doc.Stats != nil {
+		result.WriteString("\n" + i18n.T("統計") + ":\n")
+		for _, line := range statsLines(doc.Stats) {
+			result.WriteString(fmt.Sprintf("  %s: %s\n", line[0], line[1]))
+		}
+	}
+
+	if doc.Kind == KindBundle {
+		r.writeBundle(&result, doc)
+	} else {
+		r.writeMessages(&result, doc)
+	}
+
+	// フッターを追加
+	result.WriteString("--- " + i18n.T("ここまで") + " ---")
+
+	_, err := io.WriteString(w, result.String())
+	return err
+}
+
+// writeBundle writes the table of contents, the user legend and one section per source
+func (r *TextRenderer) writeBundle(result *strings.Builder, doc *Document) {
+	result.WriteString("\n" + i18n.T("目次") + ":\n")
+	for i, section := range doc.Sections {
+		result.WriteString(fmt.Sprintf(i18n.T("  %d. %s（%d件）\n"), i+1, section.SectionTitle(), section.MessageCount()))
+	}
+
+	result.WriteString("\n" + i18n.T("参加者") + ":\n")
+	for _, user := range doc.Users {
+		result.WriteString(fmt.Sprintf("  %s - %s\n", user.Author, userLegend(user)))
+	}
+	result.WriteString("\n")
+
+	for i, section := range doc.Sections {
+		result.WriteString(fmt.Sprintf("=== %d. %s ===\n", i+1, section.SectionTitle()))
+		if section.Source != "" {
+			result.WriteString(section.Source + "\n")
+		}
+		r.writeMessages(result, section)
+	}
+}
+
+// writeMessages writes the messages of a document; channel documents indent replies under their parent
+func (r *TextRenderer) writeMessages(result *strings.Builder, doc *Document) {
+	if doc.Kind == KindChannel {
+		if doc.Channel != "" {
+			result.WriteString(fmt.Sprintf(i18n.T("チャンネル: #%s\n"), doc.Channel))
+		}
+		result.WriteString("\n")
+
+		// スレッド返信はインデントして親メッセージの下に表示
+		for _, msg := range doc.Messages {
+			result.WriteString(r.formatMessage(msg))
+			result.WriteString("\n")
+
+			for _, reply := range msg.Replies {
+				lines := strings.Split(r.formatMessage(reply), "\n")
+				for i, line := range lines {
+					if i == 0 {
+						result.WriteString(fmt.Sprintf("  └─ %s\n", line))
+					} else {
+						result.WriteString(fmt.Sprintf("     %s\n", line))
+					}
+				}
+			}
+
+			result.WriteString("\n") // メッセージ間に空行を追加
+		}
+		return
+	}
+
+	result.WriteString("\n")
+	for _, msg := range flattenMessages(doc.Messages) {
+		result.WriteString(r.formatMessage(msg))
+		result.WriteString("\n\n") // メッセージ間に空行を追加
+	}
+}
+
+// formatMessage formats a single message
+func (r *TextRenderer) formatMessage(msg DocumentMessage) string {
+	// 削除済みメッセージ（返信が残っているもの）
+	if msg.Deleted {
+		return fmt.Sprintf("[%s][-]:\n%s", msg.TimeText, i18n.T("(このメッセージは削除されました)"))
+	}
+
+	// システムイベントは1行に簡略化
+	// フォーマット: [YYYY-MM-DD HH:MM:SS][@username] (システム): 本文
+	if msg.System {
+		return fmt.Sprintf("[%s][%s] (%s): %s", msg.TimeText, msg.Author, i18n.T("システム"), strings.ReplaceAll(msg.Text, "\n", " "))
+	}
+
+	// フォーマット: [YYYY-MM-DD HH:MM:SS][@username]: 本文
+	if notes := messageNotes(msg); len(notes) > 0 {
+		return fmt.Sprintf("[%s][%s] (%s):\n%s", msg.TimeText, msg.Author, strings.Join(notes, ", "), msg.Text)
+	}
+	return fmt.Sprintf("[%s][%s]:\n%s", msg.TimeText, msg.Author, msg.Text)
+}
+
+// MarkdownRenderer renders Markdown with headings per message and replies as nested lists
+type MarkdownRenderer struct{}
+
+// Render writes the document as Markdown
+func (r *MarkdownRenderer) Render(w io.Writer, doc *Document) error {
+	var result strings.Builder
+
+	result.WriteString("# " + documentHeading(doc) + "\n\n")
+	if doc.Stats != nil {
+		result.WriteString("## " + i18n.T("統計") + "\n\n")
+		for _, line := range statsLines(doc.Stats) {
+			result.WriteString(fmt.Sprintf("- **%s**: %s\n", line[0], line[1]))
+		}
+		result.WriteString("\n")
+	}
+	if doc.Kind == KindBundle {
+		r.writeBundle(&result, doc)
+	} else {
+		if doc.Channel != "" {
+			result.WriteString(fmt.Sprintf(i18n.T("チャンネル: #%s\n"), doc.Channel))
+			result.WriteString("\n")
+		}
+		r.writeMessages(&result, doc.Messages, "##")
+	}
+
+	_, err := io.WriteString(w, strings.TrimRight(result.String(), "\n")+"\n")
+	return err
+}
+
+// writeBundle writes the table of contents, the user legend table and one section per source
+func (r *MarkdownRenderer) writeBundle(result *strings.Builder, doc *Document) {
+	result.WriteString("## " + i18n.T("目次") + "\n\n")
+	for i, section := range doc.Sections {
+		result.WriteString(fmt.Sprintf(i18n.T("%d. %s（%d件）\n"), i+1, section.SectionTitle(), section.MessageCount()))
+	}
+
+	result.WriteString("\n## " + i18n.T("参加者") + "\n\n")
+	result.WriteString("| " + i18n.T("ユーザー") + " | " + i18n.T("名前") + " | ID | " + i18n.T("投稿数") + " |\n")
+	result.WriteString("|---|---|---|---:|\n")
+	for _, user := range doc.Users {
+		id := user.UserID
+		if id == "" {
+			id = user.BotID
+		}
+		cell := strings.NewReplacer("|", `\|`)
+		result.WriteString(fmt.Sprintf("| %s | %s | %s | %d |\n", cell.Replace(user.Author), cell.Replace(user.Name), id, user.Messages))
+	}
+	result.WriteString("\n")
+
+	for i, section := range doc.Sections {
+		result.WriteString(fmt.Sprintf("## %d. %s\n\n", i+1, section.SectionTitle()))
+		if section.Source != "" {
+			result.WriteString("<" + section.Source + ">\n\n")
+		}
+		r.writeMessages(result, section.Messages, "###")
+	}
+}
+
+// writeMessages writes parent messages as headings and replies as a list below them
+func (r *MarkdownRenderer) writeMessages(result *strings.Builder, messages []DocumentMessage, heading string) {
+	for _, msg := range messages {
+		result.WriteString(heading + " " + r.messageTitle(msg) + "\n\n")
+		result.WriteString(r.messageBody(msg) + "\n\n")
+
+		for _, reply := range msg.Replies {
+			result.WriteString("- **" + r.messageTitle(reply) + "**\n\n")
+			result.WriteString(indentLines(r.messageBody(reply), "  ") + "\n\n")
+		}
+	}
+}
+
+// messageTitle returns "@username · 日時 (注記)"
+func (r *MarkdownRenderer) messageTitle(msg DocumentMessage) string {
+	title := msg.Author + " · " + msg.TimeText
+	if notes := messageNotes(msg); len(notes) > 0 {
+		title += " (" + strings.Join(notes, ", ") + ")"
+	}
+	return title
+}
+
+// messageBody returns the body; system events and deleted messages are shown as quotes
+func (r *MarkdownRenderer) messageBody(msg DocumentMessage) string {
+	switch {
+	case msg.Deleted:
+		return "_" + i18n.T("(このメッセージは削除されました)") + "_"
+	case msg.System:
+		return "> _" + i18n.T("システム") + ": " + strings.ReplaceAll(msg.Text, "\n", " ") + "_"
+	case msg.Markdown != "":
+		return msg.Markdown
+	default:
+		return msg.Text
+	}
+}
+
+// indentLines indents every non-empty line of text
+func indentLines(text, indent string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = indent + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
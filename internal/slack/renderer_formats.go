@@ -0,0 +1,221 @@
+package slack
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shellme/slack-tool/internal/i18n"
+)
+
+// JSONRenderer renders the whole document, including metadata and nested replies, as JSON
+type JSONRenderer struct{}
+
+// Render writes the document as indented JSON
+func (r *JSONRenderer) Render(w io.Writer, doc *Document) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// JSONLRenderer renders one JSON object per message (replies follow their parent)
+type JSONLRenderer struct{}
+
+// Render writes the messages as JSON Lines
+func (r *JSONLRenderer) Render(w io.Writer, doc *Document) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	for _, msg := range doc.AllMessages() {
+		if err := encoder.Encode(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// csvHeader lists the columns written by CSVRenderer
+var csvHeader = []string{"ts", "thread_ts", "time", "user_id", "author", "is_bot", "subtype", "edited_at", "reply_count", "reactions", "files", "text"}
+
+// CSVRenderer renders one row per message (replies follow their parent)
+type CSVRenderer struct{}
+
+// Render writes the messages as CSV
+func (r *CSVRenderer) Render(w io.Writer, doc *Document) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, msg := range doc.AllMessages() {
+		editedAt := ""
+		if msg.EditedAt != nil {
+			editedAt = msg.EditedAt.Format(time.RFC3339)
+		}
+
+		// リアクションは "名前:件数" をセミコロン区切り、ファイルはリンク（なければ名前）
+		var reactions []string
+		for _, reaction := range msg.Reactions {
+			reactions = append(reactions, reaction.Name+":"+strconv.Itoa(reaction.Count))
+		}
+		var files []string
+		for _, file := range msg.Files {
+			if file.Permalink != "" {
+				files = append(files, file.Permalink)
+			} else {
+				files = append(files, file.Name)
+			}
+		}
+
+		row := []string{
+			msg.Timestamp,
+			msg.ThreadTimestamp,
+			msg.Time.Format(time.RFC3339),
+			msg.UserID,
+			msg.Author,
+			strconv.FormatBool(msg.IsBot),
+			msg.Subtype,
+			editedAt,
+			strconv.Itoa(msg.ReplyCount),
+			strings.Join(reactions, ";"),
+			strings.Join(files, ";"),
+			msg.Text,
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// htmlTemplate is the standalone HTML page written by HTMLRenderer
+var htmlTemplate = template.Must(template.New("document").Funcs(template.FuncMap{
+	"notes": func(msg DocumentMessage) string { return strings.Join(messageNotes(msg), ", ") },
+	"t":     i18n.T,
+}).Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<title>{{.Heading}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", "Hiragino Sans", sans-serif; max-width: 48rem; margin: 2rem auto; padding: 0 1rem; color: #1d1c1d; }
+.message { border-bottom: 1px solid #e8e8e8; padding: 0.75rem 0; }
+.meta { color: #616061; font-size: 0.875rem; }
+.author { font-weight: bold; color: #1d1c1d; }
+.text { white-space: pre-wrap; margin-top: 0.25rem; }
+.system .text, .deleted .text { color: #616061; font-style: italic; }
+.replies { margin: 0.5rem 0 0 1.5rem; padding-left: 0.75rem; border-left: 3px solid #e8e8e8; }
+.replies .message:last-child { border-bottom: none; }
+table.users { border-collapse: collapse; }
+table.users th, table.users td { border: 1px solid #e8e8e8; padding: 0.25rem 0.5rem; text-align: left; }
+.source { font-size: 0.875rem; }
+.target { background: #fff8e1; border-left: 4px solid #ecb22e; padding-left: 0.5rem; }
+</style>
+</head>
+<body>
+<h1>{{.Heading}}</h1>
+{{with .StatsLines}}<h2>{{t "統計"}}</h2>
+<ul class="stats">
+{{range .}}<li><strong>{{index . 0}}</strong>: {{index . 1}}</li>
+{{end}}</ul>
+{{end}}{{if .Sections}}<h2>{{t "目次"}}</h2>
+<ol class="toc">
+{{range .Sections}}<li><a href="#source-{{.Index}}">{{.Title}}</a> ({{.Count}})</li>
+{{end}}</ol>
+<h2>{{t "参加者"}}</h2>
+<table class="users">
+<tr><th>{{t "ユーザー"}}</th><th>{{t "名前"}}</th><th>ID</th><th>{{t "投稿数"}}</th></tr>
+{{range .Users}}<tr><td>{{.Author}}</td><td>{{.Name}}</td><td>{{if .UserID}}{{.UserID}}{{else}}{{.BotID}}{{end}}</td><td>{{.Messages}}</td></tr>
+{{end}}</table>
+{{range .Sections}}<section id="source-{{.Index}}">
+<h2>{{.Index}}. {{.Title}}</h2>
+{{if .Source}}<p class="source"><a href="{{.Source}}">{{.Source}}</a></p>
+{{end}}{{range .Messages}}{{template "message" .}}{{end}}</section>
+{{end}}{{else}}{{if .Channel}}<p class="channel">#{{.Channel}}</p>
+{{end}}{{range .Messages}}{{template "message" .}}{{end}}{{end}}</body>
+</html>
+{{define "message"}}<div class="message{{if .System}} system{{end}}{{if .Deleted}} deleted{{end}}{{if .Target}} target{{end}}" id="m{{.Timestamp}}">
+<div class="meta"><span class="author">{{.Author}}</span> <time datetime="{{.Time.Format "2006-01-02T15:04:05Z07:00"}}">{{.TimeText}}</time>{{with notes .}} ({{.}}){{end}}</div>
+<div class="text">{{.Text}}</div>
+{{if .Replies}}<div class="replies">
+{{range .Replies}}{{template "message" .}}{{end}}</div>
+{{end}}</div>
+{{end}}`))
+
+// HTMLRenderer renders a standalone HTML page
+type HTMLRenderer struct{}
+
+// Render writes the document as an HTML page
+func (r *HTMLRenderer) Render(w io.Writer, doc *Document) error {
+	var sections []htmlSection
+	for i, section := range doc.Sections {
+		sections = append(sections, htmlSection{
+			Index:    i + 1,
+			Title:    section.SectionTitle(),
+			Source:   section.Source,
+			Count:    fmt.Sprintf(i18n.T("%d件"), section.MessageCount()),
+			Messages: htmlMessages(section.Messages),
+		})
+	}
+
+	return htmlTemplate.Execute(w, struct {
+		*Document
+		Lang       string
+		Heading    string
+		Messages   []DocumentMessage
+		Sections   []htmlSection
+		StatsLines [][2]string
+	}{
+		Document:   doc,
+		Lang:       string(i18n.Current()),
+		Heading:    documentHeading(doc),
+		Messages:   htmlMessages(doc.Messages),
+		Sections:   sections,
+		StatsLines: htmlStatsLines(doc),
+	})
+}
+
+// htmlStatsLines returns the lines of the stats block (nil without --stats)
+func htmlStatsLines(doc *Document) [][2]string {
+	if doc.Stats == nil {
+		return nil
+	}
+	return statsLines(doc.Stats)
+}
+
+// htmlSection is a bundle section prepared for htmlTemplate
+type htmlSection struct {
+	Index    int
+	Title    string
+	Source   string
+	Count    string
+	Messages []DocumentMessage
+}
+
+// htmlMessages prepares messages for htmlTemplate
+func htmlMessages(messages []DocumentMessage) []DocumentMessage {
+	result := make([]DocumentMessage, len(messages))
+	for i, msg := range messages {
+		result[i] = htmlMessage(msg)
+	}
+	return result
+}
+
+// htmlMessage fills in the display text of deleted messages and system events
+func htmlMessage(msg DocumentMessage) DocumentMessage {
+	if msg.Deleted {
+		msg.Text = i18n.T("(このメッセージは削除されました)")
+	} else if msg.System {
+		msg.Text = i18n.T("システム") + ": " + msg.Text
+	}
+
+	msg.Replies = htmlMessages(msg.Replies)
+	return msg
+}
@@ -0,0 +1,220 @@
+package slack
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shellme/slack-tool/internal/i18n"
+)
+
+// DefaultResolutionEmojis are the reactions that mark a thread as resolved
+var DefaultResolutionEmojis = []string{"white_check_mark", "heavy_check_mark", "ballot_box_with_check"}
+
+// DocumentStats summarizes the messages of a document
+type DocumentStats struct {
+	Messages        int                `json:"messages"`          // 削除済み・システムイベントを除くメッセージ数
+	Replies         int                `json:"replies"`           // うちスレッド返信の数
+	Threads         int                `json:"threads,omitempty"` // 返信のあるスレッドの数（チャンネルの場合）
+	Participants    []StatsParticipant `json:"participants"`
+	FirstAt         time.Time          `json:"first_at"`
+	LastAt          time.Time          `json:"last_at"`
+	FirstText       string             `json:"-"` // 表示用の最初の投稿日時
+	LastText        string             `json:"-"` // 表示用の最後の投稿日時
+	DurationSeconds int64              `json:"duration_seconds"`
+	Reactions       []MessageReaction  `json:"reactions,omitempty"` // リアクションごとの合計（多い順）
+	ReactionTotal   int                `json:"reaction_total"`
+	Resolved        bool               `json:"resolved"`
+	ResolvedWith    []string           `json:"resolved_with,omitempty"` // 付いていた解決済みのリアクション
+	Files           []MessageFile      `json:"files,omitempty"`
+}
+
+// StatsParticipant is a participant with the number of messages and replies
+type StatsParticipant struct {
+	DocumentUser
+	Replies int `json:"replies"` // うちスレッド返信の数
+}
+
+// Duration returns the time between the first and last message
+func (s *DocumentStats) Duration() time.Duration {
+	return time.Duration(s.DurationSeconds) * time.Second
+}
+
+// ComputeStats computes statistics from the messages and reactions of a document.
+// A reaction in resolutionEmojis on any message marks the document as resolved (skin tone variants match the base emoji).
+func ComputeStats(doc *Document, resolutionEmojis []string) *DocumentStats {
+	stats := &DocumentStats{}
+	participants := make(map[string]int)
+	reactions := make(map[string]int)
+	resolved := make(map[string]bool)
+
+	for _, msg := range doc.Messages {
+		if len(msg.Replies) > 0 || msg.ReplyCount > 0 {
+			stats.Threads++
+		}
+	}
+	if doc.Kind != KindChannel {
+		stats.Threads = 0
+	}
+
+	for _, msg := range doc.AllMessages() {
+		if msg.Deleted || msg.System {
+			continue
+		}
+
+		stats.Messages++
+		isReply := msg.ThreadTimestamp != "" && msg.ThreadTimestamp != msg.Timestamp
+		if isReply {
+			stats.Replies++
+		}
+		if stats.FirstAt.IsZero() || msg.Time.Before(stats.FirstAt) {
+			stats.FirstAt, stats.FirstText = msg.Time, msg.TimeText
+		}
+		if msg.Time.After(stats.LastAt) {
+			stats.LastAt, stats.LastText = msg.Time, msg.TimeText
+		}
+
+		// 投稿者ごとの投稿数（ユーザーID・ボットID・表示名の順で識別）
+		key := msg.UserID
+		if key == "" {
+			key = msg.BotID
+		}
+		if key == "" {
+			key = msg.Author
+		}
+		i, ok := participants[key]
+		if !ok {
+			i = len(stats.Participants)
+			participants[key] = i
+			stats.Participants = append(stats.Participants, StatsParticipant{DocumentUser: DocumentUser{
+				UserID: msg.UserID,
+				BotID:  msg.BotID,
+				Author: msg.Author,
+				Name:   msg.AuthorName,
+				IsBot:  msg.IsBot,
+			}})
+		}
+		stats.Participants[i].Messages++
+		if isReply {
+			stats.Participants[i].Replies++
+		}
+
+		for _, reaction := range msg.Reactions {
+			reactions[reaction.Name] += reaction.Count
+			stats.ReactionTotal += reaction.Count
+			base, _, _ := strings.Cut(reaction.Name, "::")
+			for _, emoji := range resolutionEmojis {
+				emoji = strings.Trim(strings.TrimSpace(emoji), ":")
+				if reaction.Name == emoji || base == emoji {
+					resolved[reaction.Name] = true
+				}
+			}
+		}
+		stats.Files = append(stats.Files, msg.Files...)
+	}
+
+	if stats.Messages > 0 {
+		stats.DurationSeconds = int64(stats.LastAt.Sub(stats.FirstAt) / time.Second)
+	}
+
+	// 投稿数の多い順（同数の場合は登場順）
+	sort.SliceStable(stats.Participants, func(i, j int) bool {
+		return stats.Participants[i].Messages > stats.Participants[j].Messages
+	})
+
+	for name, count := range reactions {
+		stats.Reactions = append(stats.Reactions, MessageReaction{Name: name, Count: count})
+	}
+	sort.Slice(stats.Reactions, func(i, j int) bool {
+		if stats.Reactions[i].Count != stats.Reactions[j].Count {
+			return stats.Reactions[i].Count > stats.Reactions[j].Count
+		}
+		return stats.Reactions[i].Name < stats.Reactions[j].Name
+	})
+
+	for name := range resolved {
+		stats.ResolvedWith = append(stats.ResolvedWith, name)
+	}
+	sort.Strings(stats.ResolvedWith)
+	stats.Resolved = len(stats.ResolvedWith) > 0
+
+	return stats
+}
+
+// statsLines returns the label and value of each line of the stats block
+func statsLines(stats *DocumentStats) [][2]string {
+	messages := fmt.Sprintf(i18n.T("%d件"), stats.Messages)
+	if stats.Replies > 0 {
+		messages += fmt.Sprintf(i18n.T("（うち返信 %d件）"), stats.Replies)
+	}
+	if stats.Threads > 0 {
+		messages += fmt.Sprintf(i18n.T("、スレッド %d件"), stats.Threads)
+	}
+	lines := [][2]string{{i18n.T("メッセージ"), messages}}
+
+	if stats.Messages > 0 {
+		lines = append(lines, [2]string{i18n.T("期間"), fmt.Sprintf("%s 〜 %s (%s)", stats.FirstText, stats.LastText, formatStatsDuration(stats.Duration()))})
+	}
+
+	var participants []string
+	for _, p := range stats.Participants {
+		if p.Replies > 0 {
+			participants = append(participants, fmt.Sprintf(i18n.T("%s %d件（うち返信 %d件）"), p.Author, p.Messages, p.Replies))
+		} else {
+			participants = append(participants, fmt.Sprintf(i18n.T("%s %d件"), p.Author, p.Messages))
+		}
+	}
+	lines = append(lines, [2]string{fmt.Sprintf(i18n.T("参加者（%d人）"), len(stats.Participants)), strings.Join(participants, ", ")})
+
+	reactions := fmt.Sprintf(i18n.T("合計 %d"), stats.ReactionTotal)
+	if len(stats.Reactions) > 0 {
+		var counts []string
+		for _, reaction := range stats.Reactions {
+			counts = append(counts, fmt.Sprintf(":%s: %d", reaction.Name, reaction.Count))
+		}
+		reactions += " (" + strings.Join(counts, ", ") + ")"
+	}
+	lines = append(lines, [2]string{i18n.T("リアクション"), reactions})
+
+	resolved := i18n.T("なし")
+	if stats.Resolved {
+		resolved = i18n.T("あり") + " (:" + strings.Join(stats.ResolvedWith, ":, :") + ":)"
+	}
+	lines = append(lines, [2]string{i18n.T("解決済み"), resolved})
+
+	files := fmt.Sprintf(i18n.T("%dファイル"), len(stats.Files))
+	if len(stats.Files) > 0 {
+		var names []string
+		for _, file := range stats.Files {
+			names = append(names, file.Name)
+		}
+		files += " (" + strings.Join(names, ", ") + ")"
+	}
+	lines = append(lines, [2]string{i18n.T("ファイル"), files})
+
+	return lines
+}
+
+// formatStatsDuration formats a duration as days, hours and minutes, e.g. "1日3時間20分"
+func formatStatsDuration(d time.Duration) string {
+	if d < time.Minute {
+		return i18n.T("1分未満")
+	}
+
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+
+	var result string
+	if days > 0 {
+		result += fmt.Sprintf(i18n.T("%d日"), days)
+	}
+	if hours > 0 {
+		result += fmt.Sprintf(i18n.T("%d時間"), hours)
+	}
+	if minutes > 0 && days == 0 {
+		result += fmt.Sprintf(i18n.T("%d分"), minutes)
+	}
+	return strings.TrimSpace(result) // 英語の場合は各単位の後に空白が入る
+}
@@ -0,0 +1,118 @@
+package slack
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/shellme/slack-tool/internal/i18n"
+)
+
+func TestComputeStats(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, DefaultLocation())
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+
+	thread := DocumentMessage{
+		Timestamp: "100.000001", ThreadTimestamp: "100.000001", Time: at(0), UserID: "U1", Author: "@taro",
+		Replies: []DocumentMessage{
+			{Timestamp: "100.000002", ThreadTimestamp: "100.000001", Time: at(time.Hour), UserID: "U2", Author: "@hanako"},
+			{
+				Timestamp: "100.000003", ThreadTimestamp: "100.000001", Time: at(26*time.Hour + 5*time.Minute), UserID: "U2", Author: "@hanako",
+				Reactions: []MessageReaction{{Name: "white_check_mark::skin-tone-2", Count: 2}, {Name: "+1", Count: 1}},
+			},
+			// 削除済み・システムメッセージは数えない
+			{Timestamp: "100.000004", ThreadTimestamp: "100.000001", Time: at(48 * time.Hour), UserID: "U3", Author: "@jiro", Deleted: true},
+			{Timestamp: "100.000005", ThreadTimestamp: "100.000001", Time: at(49 * time.Hour), UserID: "U3", Author: "@jiro", System: true},
+		},
+	}
+	standalone := DocumentMessage{
+		Timestamp: "200.000001", Time: at(2 * time.Hour), BotID: "B1", Author: "@deploy", IsBot: true,
+		Reactions: []MessageReaction{{Name: "+1", Count: 2}},
+		Files:     []MessageFile{{Name: "build.log"}},
+	}
+
+	tests := []struct {
+		kind        DocumentKind
+		wantThreads int
+	}{
+		{KindChannel, 1},
+		{KindThread, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.kind), func(t *testing.T) {
+			doc := &Document{Kind: tt.kind, Messages: []DocumentMessage{thread, standalone}}
+			stats := ComputeStats(doc, DefaultResolutionEmojis)
+
+			if stats.Messages != 4 || stats.Replies != 2 || stats.Threads != tt.wantThreads {
+				t.Errorf("Messages, Replies, Threads = %d, %d, %d, want 4, 2, %d", stats.Messages, stats.Replies, stats.Threads, tt.wantThreads)
+			}
+
+			// 投稿数の多い順（同数の場合は登場順）
+			wantParticipants := []StatsParticipant{
+				{DocumentUser: DocumentUser{UserID: "U2", Author: "@hanako", Messages: 2}, Replies: 2},
+				{DocumentUser: DocumentUser{UserID: "U1", Author: "@taro", Messages: 1}},
+				{DocumentUser: DocumentUser{BotID: "B1", Author: "@deploy", IsBot: true, Messages: 1}},
+			}
+			if !reflect.DeepEqual(stats.Participants, wantParticipants) {
+				t.Errorf("Participants = %+v, want %+v", stats.Participants, wantParticipants)
+			}
+
+			if !stats.FirstAt.Equal(at(0)) || stats.Duration() != 26*time.Hour+5*time.Minute {
+				t.Errorf("FirstAt, Duration = %v, %v", stats.FirstAt, stats.Duration())
+			}
+
+			wantReactions := []MessageReaction{{Name: "+1", Count: 3}, {Name: "white_check_mark::skin-tone-2", Count: 2}}
+			if !reflect.DeepEqual(stats.Reactions, wantReactions) || stats.ReactionTotal != 5 {
+				t.Errorf("Reactions = %+v (total %d), want %+v (total 5)", stats.Reactions, stats.ReactionTotal, wantReactions)
+			}
+			if !stats.Resolved || !reflect.DeepEqual(stats.ResolvedWith, []string{"white_check_mark::skin-tone-2"}) {
+				t.Errorf("Resolved = %v %v, want the skin tone variant", stats.Resolved, stats.ResolvedWith)
+			}
+			if len(stats.Files) != 1 {
+				t.Errorf("Files = %+v, want 1 file", stats.Files)
+			}
+		})
+	}
+}
+
+func TestComputeStatsUnresolved(t *testing.T) {
+	doc := &Document{Kind: KindMessage, Messages: []DocumentMessage{
+		{Timestamp: "100.000001", UserID: "U1", Author: "@taro", Reactions: []MessageReaction{{Name: "white_check_mark", Count: 1}}},
+	}}
+	if stats := ComputeStats(doc, []string{":done:"}); stats.Resolved {
+		t.Errorf("Resolved with %v, want unresolved", stats.ResolvedWith)
+	}
+	if stats := ComputeStats(doc, []string{" :white_check_mark: "}); !stats.Resolved {
+		t.Error("Resolved = false, want true for a configured emoji with colons")
+	}
+}
+
+func TestFormatStatsDuration(t *testing.T) {
+	defer i18n.SetLanguage(i18n.Current())
+
+	tests := []struct {
+		duration time.Duration
+		ja       string
+		en       string
+	}{
+		{30 * time.Second, "1分未満", "less than a minute"},
+		{5 * time.Minute, "5分", "5m"},
+		{time.Hour, "1時間", "1h"},
+		{3*time.Hour + 20*time.Minute, "3時間20分", "3h 20m"},
+		{24 * time.Hour, "1日", "1d"},
+		// 1日以上の場合は分を省略
+		{27*time.Hour + 20*time.Minute, "1日3時間", "1d 3h"},
+	}
+
+	for _, tt := range tests {
+		i18n.SetLanguage(i18n.Japanese)
+		if got := formatStatsDuration(tt.duration); got != tt.ja {
+			t.Errorf("formatStatsDuration(%v) in Japanese = %q, want %q", tt.duration, got, tt.ja)
+		}
+		i18n.SetLanguage(i18n.English)
+		if got := formatStatsDuration(tt.duration); got != tt.en {
+			t.Errorf("formatStatsDuration(%v) in English = %q, want %q", tt.duration, got, tt.en)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package slack
+
+import (
+	"fmt"
+
+	"github.com/shellme/slack-tool/internal/i18n"
+	"github.com/slack-go/slack"
+)
+
+// SubtypeOptions controls how message subtypes are handled by the formatter
+type SubtypeOptions struct {
+	IncludeSystem     bool // 参加・退出・トピック変更などのシステムイベントを1行に簡略化して表示する
+	ShowEdited        bool // 編集されたメッセージに編集日時を表示する
+	IncludeBroadcasts bool // 親メッセージのないスレッドの外に表示される同時投稿（親が取得範囲外・--context の前後）を表示する。親がある場合はこの設定によらずスレッド内に1回だけ表示
+	IncludeDeleted    bool // 返信が残っている削除済みメッセージを表示する
+}
+
+// DefaultSubtypeOptions returns the default subtype options (system events are skipped)
+func DefaultSubtypeOptions() SubtypeOptions {
+	return SubtypeOptions{
+		IncludeSystem:     false,
+		ShowEdited:        true,
+		IncludeBroadcasts: true,
+		IncludeDeleted:    true,
+	}
+}
+
+// SubtypeCategories lists the category names accepted by ApplySubtypeCategory
+var SubtypeCategories = []string{"system", "edited", "broadcast", "deleted"}
+
+// ApplySubtypeCategory includes or excludes a category (system / edited / broadcast / deleted)
+func (o *SubtypeOptions) ApplySubtypeCategory(category string, include bool) error {
+	switch category {
+	case "system":
+		o.IncludeSystem = include
+	case "edited":
+		o.ShowEdited = include
+	case "broadcast":
+		o.IncludeBroadcasts = include
+	case "deleted":
+		o.IncludeDeleted = include
+	default:
+		return fmt.Errorf(i18n.T("無効なメッセージ種別です: %s（system / edited / broadcast / deleted のいずれかを指定してください）"), category)
+	}
+	return nil
+}
+
+// systemSubtypes lists subtypes of system events posted to a channel
+var systemSubtypes = map[string]bool{
+	"channel_join":               true,
+	"channel_leave":              true,
+	"channel_topic":              true,
+	"channel_purpose":            true,
+	"channel_name":               true,
+	"channel_archive":            true,
+	"channel_unarchive":          true,
+	"channel_convert_to_private": true,
+	"group_join":                 true,
+	"group_leave":                true,
+	"group_topic":                true,
+	"group_purpose":              true,
+	"group_name":                 true,
+	"group_archive":              true,
+	"group_unarchive":            true,
+	"pinned_item":                true,
+	"unpinned_item":              true,
+	"bot_add":                    true,
+	"bot_remove":                 true,
+	"reminder_add":               true,
+}
+
+// isSystemMessage reports whether a message is a system event such as a join or topic change
+func isSystemMessage(msg slack.Message) bool {
+	return systemSubtypes[msg.SubType]
+}
+
+// isTombstone reports whether a message is a placeholder for a deleted message that still has replies
+func isTombstone(msg slack.Message) bool {
+	return msg.SubType == "tombstone"
+}
+
+// isThreadBroadcast reports whether a message is a thread reply also sent to the channel
+func isThreadBroadcast(msg slack.Message) bool {
+	return msg.SubType == "thread_broadcast"
+}
+
+// shouldSkipMessage reports whether a message is excluded by the subtype options
+func (f *Formatter) shouldSkipMessage(msg slack.Message) bool {
+	switch {
+	case isSystemMessage(msg):
+		return !f.subtypeOptions.IncludeSystem
+	case isTombstone(msg):
+		return !f.subtypeOptions.IncludeDeleted
+	}
+	return false
+}
+
+// shouldSkipChannelCopy reports whether a thread broadcast shown outside its thread (its channel-level copy)
+// is excluded; the reply itself is always kept inside its thread
+func (f *Formatter) shouldSkipChannelCopy(msg slack.Message) bool {
+	return isThreadBroadcast(msg) && !f.subtypeOptions.IncludeBroadcasts
+}
+
+// dedupeMessages removes messages with the same timestamp, keeping the first one.
+// Thread broadcasts are returned both by conversations.history and conversations.replies.
+func dedupeMessages(messages []slack.Message) []slack.Message {
+	seen := make(map[string]bool)
+	var deduped []slack.Message
+	for _, msg := range messages {
+		if seen[msg.Timestamp] {
+			continue
+		}
+		seen[msg.Timestamp] = true
+		deduped = append(deduped, msg)
+	}
+	return deduped
+}
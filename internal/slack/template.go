@@ -0,0 +1,128 @@
+package slack
+
+import (
+	"embed"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/shellme/slack-tool/internal/i18n"
+)
+
+// builtinTemplateFS holds the templates bundled with slack-tool
+//
+//go:embed templates/*.tmpl
+var builtinTemplateFS embed.FS
+
+// TemplateExtension is the file extension of output templates
+const TemplateExtension = ".tmpl"
+
+// templateFuncs are the functions available in output templates
+var templateFuncs = template.FuncMap{
+	// t は表示言語に応じたメッセージを返す
+	"t": i18n.T,
+	// oneline は改行を空白に置き換える
+	"oneline": func(text string) string {
+		return strings.Join(strings.Fields(strings.ReplaceAll(text, "\n", " ")), " ")
+	},
+	// indent は各行の先頭に n 個の空白を追加する
+	"indent": func(n int, text string) string {
+		return indentLines(text, strings.Repeat(" ", n))
+	},
+	"join": func(sep string, items []string) string {
+		return strings.Join(items, sep)
+	},
+	"trim": strings.TrimSpace,
+	// formatTime は日時を Go のレイアウトで整形する
+	"formatTime": func(layout string, t time.Time) string {
+		return t.Format(layout)
+	},
+	// notes は「編集済み」などの注記をカンマ区切りで返す
+	"notes": func(msg DocumentMessage) string {
+		return strings.Join(messageNotes(msg), ", ")
+	},
+}
+
+// TemplateRenderer renders a document with a user-defined text/template
+type TemplateRenderer struct {
+	tmpl *template.Template
+}
+
+// NewTemplateRenderer parses a text/template; the document is passed as the template data
+func NewTemplateRenderer(name, text string) (*TemplateRenderer, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &TemplateRenderer{tmpl: tmpl}, nil
+}
+
+// Render executes the template with the document
+func (r *TemplateRenderer) Render(w io.Writer, doc *Document) error {
+	return r.tmpl.Execute(w, doc)
+}
+
+// BuiltinTemplateNames returns the names of the bundled templates
+func BuiltinTemplateNames() []string {
+	entries, err := builtinTemplateFS.ReadDir("templates")
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), TemplateExtension))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BuiltinTemplate returns the content of a bundled template
+func BuiltinTemplate(name string) (string, bool) {
+	data, err := builtinTemplateFS.ReadFile(path.Join("templates", name+TemplateExtension))
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// Parent returns the thread parent, the single message or the target of a context document (nil for channel and bundle documents)
+func (d *Document) Parent() *DocumentMessage {
+	if d.Kind == KindChannel || d.Kind == KindBundle || len(d.Messages) == 0 {
+		return nil
+	}
+	if d.Kind == KindContext {
+		for i := range d.Messages {
+			if d.Messages[i].Target {
+				return &d.Messages[i]
+			}
+		}
+	}
+	return &d.Messages[0]
+}
+
+// AllMessages returns all messages, with replies following their parent (for bundles, of all sections)
+func (d *Document) AllMessages() []DocumentMessage {
+	messages := flattenMessages(d.Messages)
+	for _, section := range d.Sections {
+		messages = append(messages, section.AllMessages()...)
+	}
+	return messages
+}
+
+// Participants returns the authors of the messages in order of first appearance
+func (d *Document) Participants() []string {
+	seen := make(map[string]bool)
+	var participants []string
+	for _, msg := range d.AllMessages() {
+		if msg.Deleted || msg.System || seen[msg.Author] {
+			continue
+		}
+		seen[msg.Author] = true
+		participants = append(participants, msg.Author)
+	}
+	return participants
+}
@@ -0,0 +1,49 @@
+package slack
+
+import (
+	"math"
+	"unicode"
+	"unicode/utf8"
+)
+
+// EstimateTokens estimates the number of tokens an LLM tokenizer produces for text.
+// ASCII words count as one token per four characters, punctuation as one token each,
+// kana as one token per character and kanji as one and a half (most tokenizers split rare kanji).
+func EstimateTokens(text string) int {
+	var tokens float64
+	word := 0
+	flushWord := func() {
+		if word > 0 {
+			tokens += math.Ceil(float64(word) / 4)
+			word = 0
+		}
+	}
+
+	for _, r := range text {
+		switch {
+		case r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			word++
+		case unicode.IsSpace(r):
+			flushWord()
+		case unicode.Is(unicode.Han, r):
+			flushWord()
+			tokens += 1.5
+		default:
+			// 記号・かな・その他の文字は1文字1トークン
+			flushWord()
+			tokens++
+		}
+	}
+	flushWord()
+
+	return int(math.Ceil(tokens))
+}
+
+// RenderTokens renders a document and returns the output with its estimated token count
+func RenderTokens(r Renderer, doc *Document) (string, int, error) {
+	content, err := RenderString(r, doc)
+	if err != nil {
+		return "", 0, err
+	}
+	return content, EstimateTokens(content), nil
+}